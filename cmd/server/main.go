@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
-	"log"
+	"flag"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,27 +15,47 @@ import (
 	"zeo-api/internal/api/middleware"
 	"zeo-api/internal/config"
 	"zeo-api/internal/core/cache"
+	"zeo-api/internal/core/jobs"
+	"zeo-api/internal/core/metrics"
+	"zeo-api/internal/core/pool"
 	"zeo-api/internal/core/runner"
+	"zeo-api/internal/utils/logging"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
 )
 
 func main() {
-	// Load configuration
-	cfg, err := config.LoadConfig("config/config.yaml")
+	checkConfig := flag.Bool("check-config", false, "validate the configuration and exit")
+
+	// Load configuration: defaults, then the YAML file, environment and flags
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
 	if err != nil {
-		log.Printf("Failed to load config from file: %v, using defaults", err)
-		cfg, err = config.LoadDefaultConfig()
-		if err != nil {
-			log.Fatalf("Failed to load default config: %v", err)
-		}
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+	if *checkConfig {
+		fmt.Println("Configuration OK")
+		return
 	}
 
+	// Initialize logging
+	logger, closeLog, err := logging.New(&cfg.Logging)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logging: %v\n", err)
+		os.Exit(1)
+	}
+	defer closeLog()
+	slog.SetDefault(logger)
+
 	// Initialize Zeo++ runner
 	zeoRunner := runner.NewZeoRunner(&cfg.Zeo)
 	if err := zeoRunner.ValidateZeoExecutable(); err != nil {
-		log.Fatalf("Zeo++ executable not found: %v", err)
+		fatal("Zeo++ executable not found", "error", err)
 	}
 
 	// Initialize cache
@@ -47,7 +69,9 @@ func main() {
 	}
 
 	router := gin.New()
-	router.Use(gin.Logger())
+	router.MaxMultipartMemory = cfg.Server.MaxMultipartMemory
+	router.Use(middleware.RequestLogger(logger))
+	router.Use(middleware.Metrics())
 	router.Use(gin.Recovery())
 
 	// CORS middleware
@@ -69,8 +93,16 @@ func main() {
 	// Global semaphore for concurrent requests
 	globalLimiter := middleware.NewGlobalSemaphore(cfg.Concurrency.MaxConcurrentUploads)
 
+	registerMetrics(cacheInstance, rateLimiter, globalLimiter)
+
+	// Worker pool and job manager for asynchronous analyses
+	workerPool := pool.NewWorkerPoolWithQueue(cfg.Concurrency.MaxWorkers, cfg.Concurrency.MaxQueueSize)
+	workerPool.Start()
+	jobManager := jobs.NewManager(workerPool, cfg.Concurrency.MaxBatchJobs)
+	go jobManager.Cleanup(time.Hour)
+
 	// Initialize base handler
-	baseHandler := handlers.NewBaseHandler(zeoRunner, cacheInstance, cfg)
+	baseHandler := handlers.NewBaseHandler(zeoRunner, cacheInstance, cfg, workerPool)
 
 	// Initialize specific handlers
 	poreDiameterHandler := handlers.NewPoreDiameterHandler(baseHandler)
@@ -82,13 +114,24 @@ func main() {
 	blockingSpheresHandler := handlers.NewBlockingSpheresHandler(baseHandler)
 	openMetalSitesHandler := handlers.NewOpenMetalSitesHandler(baseHandler)
 	poreSizeDistHandler := handlers.NewPoreSizeDistHandler(baseHandler)
+	rayAtomHandler := handlers.NewRayAtomHandler(baseHandler)
+	distanceGridHandler := handlers.NewDistanceGridHandler(baseHandler)
+	convertHandler := handlers.NewConvertHandler(baseHandler)
+	voronoiNetworkHandler := handlers.NewVoronoiNetworkHandler(baseHandler)
+	profileHandler := handlers.NewProfileHandler(baseHandler)
+	probeSweepHandler := handlers.NewProbeSweepHandler(baseHandler)
+	descriptorsHandler := handlers.NewDescriptorsHandler(baseHandler)
+	batchHandler := handlers.NewBatchHandler(baseHandler, jobManager, workerPool)
+	jobHandler := handlers.NewJobHandler(baseHandler, jobManager)
 
 	// API routes
 	api := router.Group("/api")
 	{
 		// Apply middleware
+		api.Use(middleware.ResponseDeadline(cfg.Server.HandlerTimeout()))
 		api.Use(rateLimiter.RateLimit())
 		api.Use(globalLimiter.Middleware())
+		api.Use(middleware.BodyLimit(cfg.Concurrency.MaxFileSize+middleware.FormOverhead, cfg.Server.MaxMultipartMemory))
 
 		// Analysis endpoints
 		api.POST("/pore_diameter", poreDiameterHandler.Handle)
@@ -99,7 +142,24 @@ func main() {
 		api.POST("/framework_info", frameworkInfoHandler.Handle)
 		api.POST("/blocking_spheres", blockingSpheresHandler.Handle)
 		api.POST("/open_metal_sites", openMetalSitesHandler.Handle)
-		api.POST("/pore_size_dist/download", poreSizeDistHandler.Handle)
+		api.POST("/pore_size_dist", poreSizeDistHandler.Handle)
+		api.POST("/pore_size_dist/download", poreSizeDistHandler.Download)
+		api.POST("/ray_atom", rayAtomHandler.Handle)
+		api.POST("/distance_grid/cube", distanceGridHandler.HandleCube)
+		api.POST("/distance_grid/bov", distanceGridHandler.HandleBOV)
+		api.POST("/convert", convertHandler.Handle)
+		api.POST("/voronoi_network", voronoiNetworkHandler.Handle)
+		api.POST("/voronoi_network/download", voronoiNetworkHandler.Download)
+		api.POST("/profile", profileHandler.Handle)
+		api.POST("/probe_sweep", probeSweepHandler.Handle)
+		api.POST("/descriptors", descriptorsHandler.Handle)
+
+		// Asynchronous jobs
+		api.POST("/jobs", jobHandler.Create)
+		api.GET("/jobs/:id", jobHandler.Get)
+		api.DELETE("/jobs/:id", jobHandler.Cancel)
+		api.GET("/jobs/:id/result", jobHandler.Result)
+		api.POST("/batch", batchHandler.Handle)
 	}
 
 	// Health check endpoint
@@ -114,6 +174,9 @@ func main() {
 		})
 	})
 
+	// Prometheus metrics, outside /api so scrapes are never rate limited
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
 	// Root endpoint
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -126,24 +189,42 @@ func main() {
 				"POST /api/probe_volume",
 				"POST /api/channel_analysis",
 				"POST /api/framework_info",
+				"POST /api/pore_size_dist",
 				"POST /api/pore_size_dist/download",
 				"POST /api/blocking_spheres",
 				"POST /api/open_metal_sites",
+				"POST /api/ray_atom",
+				"POST /api/distance_grid/cube",
+				"POST /api/distance_grid/bov",
+				"POST /api/convert?to=cssr|cif|v1|xyz",
+				"POST /api/voronoi_network",
+				"POST /api/voronoi_network/download",
+				"POST /api/profile",
+				"POST /api/probe_sweep",
+				"POST /api/descriptors",
+				"POST /api/jobs",
+				"GET /api/jobs/:id",
+				"DELETE /api/jobs/:id",
+				"GET /api/jobs/:id/result",
+				"POST /api/batch",
+				"GET /metrics",
 			},
 		})
 	})
 
 	// Create HTTP server
 	srv := &http.Server{
-		Addr:    cfg.Server.Host + ":" + cfg.Server.Port,
-		Handler: router,
+		Addr:         cfg.Server.Host + ":" + cfg.Server.Port,
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
 	}
 
 	// Start server in a goroutine
 	go func() {
-		log.Printf("Starting server on %s:%s", cfg.Server.Host, cfg.Server.Port)
+		slog.Info("Starting server", "addr", srv.Addr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			fatal("Failed to start server", "error", err)
 		}
 	}()
 
@@ -151,14 +232,47 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("Server forced to shutdown: %v", err)
+		slog.Error("Server forced to shutdown", "error", err)
 	}
+	jobManager.Shutdown()
+	workerPool.Stop()
+
+	slog.Info("Server exited")
+}
+
+// registerMetrics exposes the state of the cache and request limiters,
+// read on every scrape
+func registerMetrics(c *cache.Cache, rl *middleware.RateLimiter, gs *middleware.GlobalSemaphore) {
+	metrics.GaugeFunc("zeo_api_cache_entries", "Results currently held in the cache.", func() float64 {
+		total, _ := c.Stats()
+		return float64(total)
+	})
+	metrics.GaugeFunc("zeo_api_cache_entry_hits", "Hits served by the results currently held in the cache.", func() float64 {
+		_, hits := c.Stats()
+		return float64(hits)
+	})
+	metrics.CounterFunc("zeo_api_rate_limit_rejections_total", "Requests refused for exceeding the per-IP rate limit.", func() float64 {
+		return float64(rl.Rejected())
+	})
+	metrics.GaugeFunc("zeo_api_concurrent_requests", "API requests currently holding a global concurrency slot.", func() float64 {
+		return float64(gs.InUse())
+	})
+	metrics.GaugeFunc("zeo_api_concurrent_requests_limit", "Global concurrency slots available to API requests.", func() float64 {
+		return float64(gs.Capacity())
+	})
+	metrics.CounterFunc("zeo_api_concurrency_rejections_total", "API requests refused because every global concurrency slot was taken.", func() float64 {
+		return float64(gs.Rejected())
+	})
+}
 
-	log.Println("Server exited")
+// fatal logs at error level and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
@@ -15,6 +15,11 @@ func NewAccessibleVolumeHandler(base *BaseHandler) *AccessibleVolumeHandler {
 }
 
 func (h *AccessibleVolumeHandler) Handle(c *gin.Context) {
+	h.ProcessAnalysis(c, "accessible_volume", h.Params(c))
+}
+
+// Params extracts the accessible_volume parameters from the request form
+func (h *AccessibleVolumeHandler) Params(c *gin.Context) map[string]interface{} {
 	var params = make(map[string]interface{})
 
 	// Parse form parameters
@@ -42,5 +47,5 @@ func (h *AccessibleVolumeHandler) Handle(c *gin.Context) {
 		params["samples"] = 2000
 	}
 
-	h.ProcessAnalysis(c, "accessible_volume", params)
+	return params
 }
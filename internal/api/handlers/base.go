@@ -1,13 +1,22 @@
 package handlers
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"sync"
 
+	"zeo-api/internal/api/models"
 	"zeo-api/internal/config"
 	"zeo-api/internal/core/cache"
 	"zeo-api/internal/core/parser"
+	"zeo-api/internal/core/pool"
 	"zeo-api/internal/core/runner"
 	"zeo-api/internal/utils/file"
 
@@ -18,17 +27,90 @@ type BaseHandler struct {
 	zeoRunner *runner.ZeoRunner
 	cache     *cache.Cache
 	config    *config.Config
+	pool      *pool.WorkerPool
 }
 
-func NewBaseHandler(zeoRunner *runner.ZeoRunner, cacheInstance *cache.Cache, cfg *config.Config) *BaseHandler {
+// NewBaseHandler creates the shared handler state. Requests running several
+// analyses fan them out on workerPool, so that they share one limit on Zeo++
+// processes with jobs and batches.
+func NewBaseHandler(zeoRunner *runner.ZeoRunner, cacheInstance *cache.Cache, cfg *config.Config, workerPool *pool.WorkerPool) *BaseHandler {
 	return &BaseHandler{
 		zeoRunner: zeoRunner,
 		cache:     cacheInstance,
 		config:    cfg,
+		pool:      workerPool,
 	}
 }
 
+// stdoutCacheKey stores the Zeo++ console output next to the output files in
+// a cache entry; it cannot collide with an output file name
+const stdoutCacheKey = ":stdout"
+
+// analysisError carries the HTTP status an analysis failure should map to
+type analysisError struct {
+	status  int
+	message string
+	stdout  string
+}
+
+func (e *analysisError) Error() string {
+	return e.message
+}
+
 func (h *BaseHandler) ProcessAnalysis(c *gin.Context, analysisType string, params map[string]interface{}) {
+	if err := h.parseCommonParams(c, params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("invalid parameters: %v", err),
+		})
+		return
+	}
+	replicates, tolerance, err := parseReplicateParams(c, analysisType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("invalid parameters: %v", err),
+		})
+		return
+	}
+
+	savedPath, ok := h.saveStructureFile(c, analysisType)
+	if !ok {
+		return
+	}
+	defer file.CleanupFile(savedPath)
+
+	if replicates != 0 {
+		h.respondReplicates(c, savedPath, analysisType, params, replicates, tolerance)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Zeo.Timeout)
+	defer cancel()
+
+	result, cached, err := h.runAnalysis(ctx, savedPath, analysisType, params)
+	if respondInterrupted(c) {
+		return
+	}
+	if err != nil {
+		respondAnalysisError(c, err)
+		return
+	}
+
+	response := gin.H{
+		"success": true,
+		"data":    result,
+		"cached":  cached,
+	}
+	if probe := probeInfo(params); probe != nil {
+		response["probe"] = probe
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// saveStructureFile validates and stores the uploaded structure_file, writing
+// the error response itself when it returns false
+func (h *BaseHandler) saveStructureFile(c *gin.Context, prefix string) (string, bool) {
 	// Get uploaded file
 	fileHeader, err := c.FormFile("structure_file")
 	if err != nil {
@@ -36,7 +118,15 @@ func (h *BaseHandler) ProcessAnalysis(c *gin.Context, analysisType string, param
 			"success": false,
 			"error":   "structure_file is required",
 		})
-		return
+		return "", false
+	}
+
+	if fileHeader.Size > h.config.Concurrency.MaxFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("structure_file exceeds the maximum size of %d bytes", h.config.Concurrency.MaxFileSize),
+		})
+		return "", false
 	}
 
 	// Validate file extension
@@ -45,107 +135,291 @@ func (h *BaseHandler) ProcessAnalysis(c *gin.Context, analysisType string, param
 			"success": false,
 			"error":   "invalid file format. Supported: .cif, .cssr, .v1, .arc",
 		})
-		return
+		return "", false
 	}
 
 	// Save uploaded file
-	savedPath, err := file.SaveUploadedFile(fileHeader, analysisType)
+	savedPath, err := file.SaveUploadedFile(fileHeader, prefix)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   fmt.Sprintf("failed to save file: %v", err),
 		})
-		return
+		return "", false
+	}
+	return savedPath, true
+}
+
+// runAnalysis executes one analysis on a saved structure, consulting and
+// filling the cache, and returns the parsed result
+func (h *BaseHandler) runAnalysis(ctx context.Context, savedPath string, analysisType string, params map[string]interface{}) (interface{}, bool, error) {
+	return h.analyze(ctx, savedPath, analysisType, params, true)
+}
+
+// analyze is runAnalysis with the cache optional, for callers that need a
+// fresh Zeo++ run every time
+func (h *BaseHandler) analyze(ctx context.Context, savedPath string, analysisType string, params map[string]interface{}, useCache bool) (_ interface{}, _ bool, err error) {
+	entry := newAnalysisLog(analysisType, params)
+	defer func() { entry.finish(err) }()
+
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
 	}
-	defer file.CleanupFile(savedPath)
 
 	// Build Zeo++ arguments
 	zeoArgs, err := runner.BuildZeoArgs(analysisType, params)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   fmt.Sprintf("invalid parameters: %v", err),
-		})
-		return
+		return nil, false, &analysisError{
+			status:  http.StatusBadRequest,
+			message: fmt.Sprintf("invalid parameters: %v", err),
+		}
 	}
-	outputFiles := getOutputFiles(analysisType)
+	outputFiles := getOutputFiles(analysisType, params)
 
-	// Generate cache key
-	cacheKey := cache.GenerateCacheKey(savedPath, zeoArgs)
+	// Generate cache key from structure content, arguments and Zeo++ version
+	structureHash, err := file.GenerateFileHash(savedPath)
+	if err != nil {
+		return nil, false, &analysisError{
+			status:  http.StatusInternalServerError,
+			message: fmt.Sprintf("failed to hash structure file: %v", err),
+		}
+	}
+	zeoVersion, err := h.zeoRunner.Version()
+	if err != nil {
+		return nil, false, &analysisError{
+			status:  http.StatusInternalServerError,
+			message: fmt.Sprintf("failed to identify Zeo++ version: %v", err),
+		}
+	}
+	entry.structureHash = structureHash
+	inputFiles := runner.BuildInputFiles(params)
+	cacheKey := cache.GenerateCacheKey(structureHash, zeoArgs, inputFiles, zeoVersion)
 
 	// Check cache
-	if h.config.Cache.Enabled {
+	entry.cache = cacheBypassed
+	if useCache && h.config.Cache.Enabled {
+		entry.cache = cacheMiss
 		if cachedData, found := h.cache.Get(cacheKey); found {
 			if parsed, ok := cachedData[outputFiles[0]]; ok {
-				result, err := parser.ParseOutputFile(analysisType, string(parsed))
+				result, err := parseOutput(analysisType, string(parsed), string(cachedData[stdoutCacheKey]), params)
 				if err == nil {
-					c.JSON(http.StatusOK, gin.H{
-						"success": true,
-						"data":    result,
-						"cached":  true,
-					})
-					return
+					entry.cache = cacheHit
+					return result, true, nil
 				}
 			}
 		}
 	}
 
 	// Execute Zeo++ analysis
-	ctx, cancel := context.WithTimeout(context.Background(), h.config.Zeo.Timeout)
-	defer cancel()
-
-	result, err := h.zeoRunner.RunCommand(ctx, savedPath, zeoArgs, outputFiles)
+	result, err := h.zeoRunner.RunCommandWithInputs(ctx, savedPath, zeoArgs, outputFiles, inputFiles)
+	if result != nil {
+		entry.recordRun(result)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   fmt.Sprintf("Zeo++ execution failed: %v", err),
-		})
-		return
+		return nil, false, &analysisError{
+			status:  http.StatusInternalServerError,
+			message: fmt.Sprintf("Zeo++ execution failed: %v", err),
+		}
 	}
 
 	if !result.Success {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   fmt.Sprintf("Zeo++ error: %s", result.Stderr),
-			"stdout":  result.Stdout,
-		})
-		return
+		return nil, false, &analysisError{
+			status:  http.StatusInternalServerError,
+			message: fmt.Sprintf("Zeo++ error: %s", result.Stderr),
+			stdout:  result.Stdout,
+		}
 	}
 
 	// Parse and cache results
 	mainOutput := outputFiles[0]
-	if outputData, exists := result.OutputFiles[mainOutput]; exists {
-		parsedResult, err := parser.ParseOutputFile(analysisType, string(outputData))
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"error":   fmt.Sprintf("failed to parse results: %v", err),
-			})
-			return
+	outputData, exists := result.OutputFiles[mainOutput]
+	if !exists {
+		return nil, false, &analysisError{
+			status:  http.StatusInternalServerError,
+			message: "no output generated from Zeo++",
 		}
+	}
 
-		// Cache the results
-		if h.config.Cache.Enabled {
-			cacheData := map[string][]byte{
-				mainOutput: outputData,
-			}
-			h.cache.Set(cacheKey, cacheData)
+	parsedResult, err := parseOutput(analysisType, string(outputData), result.Stdout, params)
+	if err != nil {
+		return nil, false, &analysisError{
+			status:  http.StatusInternalServerError,
+			message: fmt.Sprintf("failed to parse results: %v", err),
 		}
+	}
+
+	// Cache the results
+	if h.config.Cache.Enabled {
+		cacheData := map[string][]byte{
+			mainOutput:     outputData,
+			stdoutCacheKey: []byte(result.Stdout),
+		}
+		h.cache.Set(cacheKey, cacheData)
+	}
+
+	return parsedResult, false, nil
+}
 
-		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"data":    parsedResult,
-			"cached":  false,
+type analysisRun struct {
+	analysisType string
+	params       map[string]interface{}
+	noCache      bool
+}
+
+// runConcurrently runs analyses on one saved structure across the worker
+// pool and returns their outcomes in the order of runs. Every run has its
+// own job directory, so runs never interfere.
+func (h *BaseHandler) runConcurrently(ctx context.Context, savedPath string, runs []analysisRun) []models.APIResponse {
+	outcomes := make([]models.APIResponse, len(runs))
+	var wg sync.WaitGroup
+	for i, run := range runs {
+		wg.Add(1)
+		task := pool.Task{
+			ID: run.analysisType,
+			Func: func() error {
+				defer wg.Done()
+
+				runCtx, cancel := context.WithTimeout(ctx, h.config.Zeo.Timeout)
+				defer cancel()
+
+				data, cached, err := h.analyze(runCtx, savedPath, run.analysisType, run.params, !run.noCache)
+				outcomes[i] = models.APIResponse{Success: err == nil, Data: data, Cached: cached, Probe: probeInfo(run.params)}
+				if err != nil {
+					outcomes[i].Error = err.Error()
+				}
+				return nil
+			},
+			Cancel: func() {
+				outcomes[i] = models.APIResponse{Error: "server shutting down"}
+				wg.Done()
+			},
+		}
+		if err := h.pool.SubmitWithContext(ctx, task); err != nil {
+			outcomes[i] = models.APIResponse{Error: fmt.Sprintf("failed to queue analysis: %v", err)}
+			wg.Done()
+		}
+	}
+	wg.Wait()
+
+	return outcomes
+}
+
+// statusClientClosedRequest is logged for requests whose client went away
+// before the response was ready
+const statusClientClosedRequest = 499
+
+// respondInterrupted ends a synchronous request whose context is done,
+// either because the client disconnected or because the analyses would not
+// finish within the server's write timeout. It reports whether it did.
+func respondInterrupted(c *gin.Context) bool {
+	switch c.Request.Context().Err() {
+	case nil:
+		return false
+	case context.DeadlineExceeded:
+		c.JSON(http.StatusGatewayTimeout, gin.H{
+			"success": false,
+			"error":   "analyses did not finish within the server's write timeout; queue long analyses through /api/jobs or split the request",
 		})
-	} else {
+	default:
+		c.AbortWithStatus(statusClientClosedRequest)
+	}
+	return true
+}
+
+// parseOutput parses a Zeo++ output and applies the parameters that only
+// shape the parsed result, which are therefore not part of the cache key
+func parseOutput(analysisType, data, stdout string, params map[string]interface{}) (interface{}, error) {
+	result, err := parser.ParseOutputFile(analysisType, data, stdout)
+	if err != nil {
+		return nil, err
+	}
+	if psd, ok := result.(*parser.PoreSizeDistributionResult); ok {
+		if bins, ok := params["bins"].(int); ok {
+			return parser.CoarsenPoreSizeDistribution(psd, bins), nil
+		}
+	}
+	return result, nil
+}
+
+// flattenResult converts a parsed result to its JSON form and flattens nested
+// objects into "prefix.field.subfield" keys. Lists are expanded into
+// "key[i]" entries when expandLists is set and kept whole otherwise.
+func flattenResult(prefix string, result interface{}, expandLists bool) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var data interface{}
+	if err := json.Unmarshal(encoded, &data); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]interface{})
+	var flatten func(key string, value interface{})
+	flatten = func(key string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for field, inner := range v {
+				if key != "" {
+					field = key + "." + field
+				}
+				flatten(field, inner)
+			}
+		case []interface{}:
+			if !expandLists {
+				out[key] = v
+				return
+			}
+			for i, inner := range v {
+				flatten(fmt.Sprintf("%s[%d]", key, i), inner)
+			}
+		default:
+			out[key] = v
+		}
+	}
+	flatten(prefix, data)
+	return out, nil
+}
+
+func respondAnalysisError(c *gin.Context, err error) {
+	var aerr *analysisError
+	if !errors.As(err, &aerr) {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
-			"error":   "no output generated from Zeo++",
+			"error":   err.Error(),
 		})
+		return
+	}
+
+	body := gin.H{
+		"success": false,
+		"error":   aerr.message,
+	}
+	if aerr.stdout != "" {
+		body["stdout"] = aerr.stdout
 	}
+	c.JSON(aerr.status, body)
 }
 
-func getOutputFiles(analysisType string) []string {
+// analysisParamParsers maps every analysis with a JSON result to the function
+// that extracts its parameters from a request form
+func analysisParamParsers(base *BaseHandler) map[string]func(c *gin.Context) map[string]interface{} {
+	return map[string]func(c *gin.Context) map[string]interface{}{
+		"pore_diameter":     NewPoreDiameterHandler(base).Params,
+		"surface_area":      NewSurfaceAreaHandler(base).Params,
+		"accessible_volume": NewAccessibleVolumeHandler(base).Params,
+		"probe_volume":      NewProbeVolumeHandler(base).Params,
+		"channel_analysis":  NewChannelAnalysisHandler(base).Params,
+		"framework_info":    NewFrameworkInfoHandler(base).Params,
+		"blocking_spheres":  NewBlockingSpheresHandler(base).Params,
+		"open_metal_sites":  NewOpenMetalSitesHandler(base).Params,
+		"pore_size_dist":    NewPoreSizeDistHandler(base).Params,
+		"ray_atom":          NewRayAtomHandler(base).Params,
+		"voronoi_network":   NewVoronoiNetworkHandler(base).Params,
+	}
+}
+
+func getOutputFiles(analysisType string, params map[string]interface{}) []string {
 	switch analysisType {
 	case "pore_diameter":
 		return []string{"output.res"}
@@ -155,6 +429,8 @@ func getOutputFiles(analysisType string) []string {
 		return []string{"output.vol"}
 	case "probe_volume":
 		return []string{"output.volpo"}
+	case "ray_atom":
+		return []string{"output.ray_atom"}
 	case "channel_analysis":
 		return []string{"output.chan"}
 	case "framework_info":
@@ -164,60 +440,69 @@ func getOutputFiles(analysisType string) []string {
 	case "open_metal_sites":
 		return []string{"output.oms"}
 	case "pore_size_dist":
-		return []string{"output.psd"}
+		// -psd writes its histogram to the given name plus "_histo"
+		return []string{"output.psd_histo"}
+	case "voronoi_network":
+		return []string{"output.nt2"}
+	case "convert":
+		format, _ := params["format"].(string)
+		return []string{"output." + format}
+	case "grid_gaussian":
+		return []string{runner.StructureBaseName + "*.cube"}
+	case "grid_bov":
+		return []string{runner.StructureBaseName + "*.bov", runner.StructureBaseName + "*.distances"}
 	default:
 		return []string{"output"}
 	}
 }
 
 func (h *BaseHandler) ProcessFileDownload(c *gin.Context, analysisType string, params map[string]interface{}) {
-	// Get uploaded file
-	fileHeader, err := c.FormFile("structure_file")
-	if err != nil {
+	if err := h.parseCommonParams(c, params); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
-			"error":   "structure_file is required",
+			"error":   fmt.Sprintf("invalid parameters: %v", err),
 		})
 		return
 	}
 
-	// Validate file extension
-	if !file.IsValidStructureFile(fileHeader.Filename) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "invalid file format. Supported: .cif, .cssr, .v1, .arc",
-		})
-		return
-	}
-
-	// Save uploaded file
-	savedPath, err := file.SaveUploadedFile(fileHeader, analysisType)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   fmt.Sprintf("failed to save file: %v", err),
-		})
+	savedPath, ok := h.saveStructureFile(c, analysisType)
+	if !ok {
 		return
 	}
 	defer file.CleanupFile(savedPath)
 
+	entry := newAnalysisLog(analysisType, params)
+	entry.cache = cacheBypassed
+	var runErr error
+	defer func() { entry.finish(runErr) }()
+	entry.structureHash, _ = file.GenerateFileHash(savedPath)
+
 	// Build Zeo++ arguments
 	zeoArgs, err := runner.BuildZeoArgs(analysisType, params)
 	if err != nil {
+		runErr = err
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
 			"error":   fmt.Sprintf("invalid parameters: %v", err),
 		})
 		return
 	}
-	outputFiles := getOutputFiles(analysisType)
+	outputFiles := getOutputFiles(analysisType, params)
 
 	// Execute Zeo++ analysis
-	ctx, cancel := context.WithTimeout(context.Background(), h.config.Zeo.Timeout)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.Zeo.Timeout)
 	defer cancel()
 
-	result, err := h.zeoRunner.RunCommand(ctx, savedPath, zeoArgs, outputFiles)
+	result, err := h.zeoRunner.RunCommandWithInputs(ctx, savedPath, zeoArgs, outputFiles, runner.BuildInputFiles(params))
+	if result != nil {
+		entry.recordRun(result)
+	}
+	if respondInterrupted(c) {
+		runErr = c.Request.Context().Err()
+		return
+	}
 	if err != nil {
+		runErr = err
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   fmt.Sprintf("Zeo++ execution failed: %v", err),
@@ -226,6 +511,7 @@ func (h *BaseHandler) ProcessFileDownload(c *gin.Context, analysisType string, p
 	}
 
 	if !result.Success {
+		runErr = fmt.Errorf("Zeo++ error: %s", result.Stderr)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   fmt.Sprintf("Zeo++ error: %s", result.Stderr),
@@ -234,17 +520,65 @@ func (h *BaseHandler) ProcessFileDownload(c *gin.Context, analysisType string, p
 		return
 	}
 
-	// Serve the file
-	mainOutput := outputFiles[0]
-	if outputData, exists := result.OutputFiles[mainOutput]; exists {
-		filename := fmt.Sprintf("%s_%s", analysisType, mainOutput)
-		c.Header("Content-Type", "application/octet-stream")
-		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
-		c.Data(http.StatusOK, "application/octet-stream", outputData)
-	} else {
+	if len(result.OutputFiles) == 0 {
+		runErr = fmt.Errorf("no output generated from Zeo++")
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "no output generated from Zeo++",
 		})
+		return
+	}
+
+	if probe := probeInfo(params); probe != nil {
+		c.Header("X-Probe-Name", probe.Name)
+		c.Header("X-Probe-Radius", strconv.FormatFloat(probe.Radius, 'g', -1, 64))
+	}
+
+	// Serve a single output as-is
+	if len(result.OutputFiles) == 1 {
+		for name, outputData := range result.OutputFiles {
+			filename := fmt.Sprintf("%s_%s", analysisType, name)
+			c.Header("Content-Type", "application/octet-stream")
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+			c.Data(http.StatusOK, "application/octet-stream", outputData)
+		}
+		return
+	}
+
+	// Bundle multi-file outputs into one zip archive
+	bundle, err := zipOutputFiles(result.OutputFiles)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("failed to bundle output files: %v", err),
+		})
+		return
+	}
+	filename := fmt.Sprintf("%s.zip", analysisType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Data(http.StatusOK, "application/zip", bundle)
+}
+
+func zipOutputFiles(outputFiles map[string][]byte) ([]byte, error) {
+	names := make([]string, 0, len(outputFiles))
+	for name := range outputFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(outputFiles[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
 	}
+	return buf.Bytes(), nil
 }
@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"zeo-api/internal/api/models"
+	"zeo-api/internal/core/jobs"
+	"zeo-api/internal/core/pool"
+	"zeo-api/internal/core/runner"
+	"zeo-api/internal/utils/file"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBatchStructures bounds the number of structures in one batch archive
+const maxBatchStructures = 100000
+
+type BatchHandler struct {
+	*BaseHandler
+	jobs     *jobs.Manager
+	pool     *pool.WorkerPool
+	analyses map[string]func(c *gin.Context) map[string]interface{}
+}
+
+func NewBatchHandler(base *BaseHandler, manager *jobs.Manager, workerPool *pool.WorkerPool) *BatchHandler {
+	return &BatchHandler{
+		BaseHandler: base,
+		jobs:        manager,
+		pool:        workerPool,
+		analyses:    analysisParamParsers(base),
+	}
+}
+
+// BatchRow is the outcome of screening one structure of a batch
+type BatchRow struct {
+	Structure string                        `json:"structure"`
+	Success   bool                          `json:"success"`
+	Error     string                        `json:"error,omitempty"`
+	Results   map[string]models.APIResponse `json:"results"`
+}
+
+// BatchResult is the result of a batch job, one row per structure in
+// archive order
+type BatchResult struct {
+	Total     int               `json:"total"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Probe     *models.ProbeInfo `json:"probe,omitempty"`
+	Rows      []BatchRow        `json:"rows"`
+}
+
+// Handle unpacks an uploaded .zip or .tar.gz of structures and queues a job
+// running the requested analyses on every structure across the worker pool
+func (h *BatchHandler) Handle(c *gin.Context) {
+	specs, err := h.parseAnalysisSpecs(c.PostForm("analyses"), h.analyses)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	common := make(map[string]interface{})
+	if err := h.parseCommonParams(c, common); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("invalid parameters: %v", err),
+		})
+		return
+	}
+
+	// Reject invalid parameters before the archive is unpacked, rather than
+	// failing every structure inside the job
+	for _, analysisType := range sortedKeys(specs) {
+		if _, err := runner.BuildZeoArgs(analysisType, mergeParams(common, specs[analysisType])); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("invalid parameters: %s: %v", analysisType, err),
+			})
+			return
+		}
+	}
+
+	fileHeader, err := c.FormFile("archive")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "archive is required",
+		})
+		return
+	}
+	if fileHeader.Size > h.config.Concurrency.MaxFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("archive exceeds the maximum size of %d bytes", h.config.Concurrency.MaxFileSize),
+		})
+		return
+	}
+	if !file.IsValidArchiveFile(fileHeader.Filename) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "invalid archive format. Supported: .zip, .tar.gz, .tgz",
+		})
+		return
+	}
+
+	if err := os.MkdirAll(h.config.Zeo.Workdir, 0755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("failed to create workspace: %v", err),
+		})
+		return
+	}
+	batchDir, err := os.MkdirTemp(h.config.Zeo.Workdir, "batch_")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("failed to create batch directory: %v", err),
+		})
+		return
+	}
+
+	structures, err := h.extractArchive(c, fileHeader, batchDir)
+	if err != nil {
+		_ = os.RemoveAll(batchDir)
+		status := http.StatusInternalServerError
+		if errors.Is(err, file.ErrInvalidArchive) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	job, err := h.jobs.Coordinate("batch", func(ctx context.Context, report func(done, total int)) (interface{}, error) {
+		defer os.RemoveAll(batchDir)
+		return h.screen(ctx, structures, specs, common, report)
+	})
+	if err != nil {
+		_ = os.RemoveAll(batchDir)
+		if errors.Is(err, jobs.ErrTooManyJobs) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"success":     false,
+				"error":       fmt.Sprintf("at most %d batch and replicate jobs can run at once", h.config.Concurrency.MaxBatchJobs),
+				"retry_after": "60s",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("failed to submit job: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+func (h *BatchHandler) extractArchive(c *gin.Context, fileHeader *multipart.FileHeader, batchDir string) ([]file.ExtractedFile, error) {
+	archivePath := filepath.Join(batchDir, "upload"+archiveExtension(fileHeader.Filename))
+	if err := c.SaveUploadedFile(fileHeader, archivePath); err != nil {
+		return nil, fmt.Errorf("failed to save archive: %w", err)
+	}
+	defer file.CleanupFile(archivePath)
+
+	return file.ExtractStructures(archivePath, filepath.Join(batchDir, "structures"), file.ArchiveLimits{
+		MaxFiles:     maxBatchStructures,
+		MaxFileSize:  h.config.Concurrency.MaxFileSize,
+		MaxTotalSize: 10 * h.config.Concurrency.MaxFileSize,
+	})
+}
+
+func archiveExtension(filename string) string {
+	lower := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"):
+		return ".tar.gz"
+	case strings.HasSuffix(lower, ".tgz"):
+		return ".tgz"
+	default:
+		return ".zip"
+	}
+}
+
+// screen runs every analysis on every structure, one pool task per structure
+func (h *BatchHandler) screen(ctx context.Context, structures []file.ExtractedFile, specs map[string]map[string]interface{}, common map[string]interface{}, report func(done, total int)) (*BatchResult, error) {
+	rows := make([]BatchRow, len(structures))
+	total := len(structures)
+	report(0, total)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+	var stopped atomic.Bool
+	var submitErr error
+
+	for i, structure := range structures {
+		wg.Add(1)
+		task := pool.Task{
+			ID: structure.Name,
+			Func: func() error {
+				defer wg.Done()
+				rows[i] = h.screenOne(ctx, structure, specs, common)
+
+				mu.Lock()
+				done++
+				report(done, total)
+				mu.Unlock()
+				return nil
+			},
+			Cancel: func() {
+				stopped.Store(true)
+				wg.Done()
+			},
+		}
+		if err := h.pool.SubmitWithContext(ctx, task); err != nil {
+			wg.Done()
+			submitErr = err
+			break
+		}
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if stopped.Load() {
+		return nil, errors.New("server shut down before the batch finished")
+	}
+	if submitErr != nil {
+		return nil, fmt.Errorf("failed to queue structures: %w", submitErr)
+	}
+
+	result := &BatchResult{Total: total, Probe: probeInfo(common), Rows: rows}
+	for _, row := range rows {
+		if row.Success {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+func (h *BatchHandler) screenOne(ctx context.Context, structure file.ExtractedFile, specs map[string]map[string]interface{}, common map[string]interface{}) BatchRow {
+	row := BatchRow{
+		Structure: structure.Name,
+		Success:   true,
+		Results:   make(map[string]models.APIResponse, len(specs)),
+	}
+
+	var errs []string
+	for _, analysisType := range sortedKeys(specs) {
+		runCtx, cancel := context.WithTimeout(ctx, h.config.Zeo.Timeout)
+		params := mergeParams(common, specs[analysisType])
+		data, cached, err := h.runAnalysis(runCtx, structure.Path, analysisType, params)
+		cancel()
+
+		entry := models.APIResponse{Success: err == nil, Data: data, Cached: cached, Probe: probeInfo(params)}
+		if err != nil {
+			entry.Error = err.Error()
+			row.Success = false
+			errs = append(errs, fmt.Sprintf("%s: %v", analysisType, err))
+		}
+		row.Results[analysisType] = entry
+	}
+	row.Error = strings.Join(errs, "; ")
+
+	return row
+}
+
+func sortedKeys(specs map[string]map[string]interface{}) []string {
+	keys := make([]string, 0, len(specs))
+	for key := range specs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteJSONLines writes one JSON object per row
+func (r *BatchResult) WriteJSONLines(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, row := range r.Rows {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCSV writes one line per row. Analysis results are flattened into
+// "<analysis>.<field>" columns; list values are written as JSON.
+func (r *BatchResult) WriteCSV(w io.Writer) error {
+	flatRows := make([]map[string]string, len(r.Rows))
+	columnSet := make(map[string]bool)
+	for i, row := range r.Rows {
+		flat := make(map[string]string)
+		for analysisType, entry := range row.Results {
+			if entry.Data == nil {
+				continue
+			}
+			values, err := flattenResult(analysisType, entry.Data, false)
+			if err != nil {
+				return err
+			}
+			for column, value := range values {
+				flat[column] = formatCSVValue(value)
+			}
+		}
+		for column := range flat {
+			columnSet[column] = true
+		}
+		flatRows[i] = flat
+	}
+
+	columns := make([]string, 0, len(columnSet))
+	for column := range columnSet {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"structure", "success", "error"}, columns...)); err != nil {
+		return err
+	}
+	for i, row := range r.Rows {
+		record := []string{row.Structure, strconv.FormatBool(row.Success), row.Error}
+		for _, column := range columns {
+			record = append(record, flatRows[i][column])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatCSVValue(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -15,6 +15,11 @@ func NewBlockingSpheresHandler(base *BaseHandler) *BlockingSpheresHandler {
 }
 
 func (h *BlockingSpheresHandler) Handle(c *gin.Context) {
+	h.ProcessAnalysis(c, "blocking_spheres", h.Params(c))
+}
+
+// Params extracts the blocking_spheres parameters from the request form
+func (h *BlockingSpheresHandler) Params(c *gin.Context) map[string]interface{} {
 	var params = make(map[string]interface{})
 
 	// Parse form parameters
@@ -30,5 +35,5 @@ func (h *BlockingSpheresHandler) Handle(c *gin.Context) {
 		params["probe_radius"] = 1.86
 	}
 
-	h.ProcessAnalysis(c, "blocking_spheres", params)
+	return params
 }
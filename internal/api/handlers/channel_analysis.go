@@ -15,6 +15,11 @@ func NewChannelAnalysisHandler(base *BaseHandler) *ChannelAnalysisHandler {
 }
 
 func (h *ChannelAnalysisHandler) Handle(c *gin.Context) {
+	h.ProcessAnalysis(c, "channel_analysis", h.Params(c))
+}
+
+// Params extracts the channel_analysis parameters from the request form
+func (h *ChannelAnalysisHandler) Params(c *gin.Context) map[string]interface{} {
 	var params = make(map[string]interface{})
 
 	// Parse form parameters
@@ -30,5 +35,5 @@ func (h *ChannelAnalysisHandler) Handle(c *gin.Context) {
 		params["probe_radius"] = 1.21
 	}
 
-	h.ProcessAnalysis(c, "channel_analysis", params)
+	return params
 }
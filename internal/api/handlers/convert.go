@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConvertHandler struct {
+	*BaseHandler
+}
+
+func NewConvertHandler(base *BaseHandler) *ConvertHandler {
+	return &ConvertHandler{BaseHandler: base}
+}
+
+// Handle converts the uploaded structure with Zeo++ and streams back the
+// converted file
+func (h *ConvertHandler) Handle(c *gin.Context) {
+	h.ProcessFileDownload(c, "convert", h.Params(c))
+}
+
+// Params extracts the convert parameters from the request; the target
+// format comes from the "to" query parameter or form field
+func (h *ConvertHandler) Params(c *gin.Context) map[string]interface{} {
+	var params = make(map[string]interface{})
+
+	format := c.Query("to")
+	if format == "" {
+		format = c.PostForm("to")
+	}
+	params["format"] = strings.ToLower(strings.TrimPrefix(format, "."))
+
+	return params
+}
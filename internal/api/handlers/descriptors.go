@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"zeo-api/internal/api/models"
+	"zeo-api/internal/core/parser"
+	"zeo-api/internal/utils/file"
+
+	"github.com/gin-gonic/gin"
+)
+
+// descriptorAnalyses are the analyses descriptors are derived from
+var descriptorAnalyses = []string{"pore_diameter", "surface_area", "accessible_volume", "probe_volume", "channel_analysis"}
+
+type DescriptorsHandler struct {
+	*BaseHandler
+	analyses map[string]func(c *gin.Context) map[string]interface{}
+}
+
+func NewDescriptorsHandler(base *BaseHandler) *DescriptorsHandler {
+	return &DescriptorsHandler{
+		BaseHandler: base,
+		analyses:    analysisParamParsers(base),
+	}
+}
+
+// Handle runs -res, -sa, -vol, -volpo and -chan on one uploaded structure and
+// returns the derived descriptor set alongside the raw results. Descriptors
+// whose inputs failed are left out; the failure shows in the raw results.
+func (h *DescriptorsHandler) Handle(c *gin.Context) {
+	common := make(map[string]interface{})
+	if err := h.parseCommonParams(c, common); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("invalid parameters: %v", err),
+		})
+		return
+	}
+
+	savedPath, ok := h.saveStructureFile(c, "descriptors")
+	if !ok {
+		return
+	}
+	defer file.CleanupFile(savedPath)
+
+	// Request-wide parameters such as a named probe take precedence over
+	// each analysis's defaults
+	runs := make([]analysisRun, len(descriptorAnalyses))
+	for i, analysisType := range descriptorAnalyses {
+		runs[i] = analysisRun{analysisType: analysisType, params: mergeParams(h.analyses[analysisType](c), common)}
+	}
+	outcomes := h.runConcurrently(c.Request.Context(), savedPath, runs)
+	if respondInterrupted(c) {
+		return
+	}
+
+	results := make(map[string]models.APIResponse, len(runs))
+	var inputs parser.DescriptorInputs
+	succeeded := 0
+	for i, run := range runs {
+		results[run.analysisType] = outcomes[i]
+		if !outcomes[i].Success {
+			continue
+		}
+		succeeded++
+		switch data := outcomes[i].Data.(type) {
+		case *parser.PoreDiameterResult:
+			inputs.PoreDiameter = data
+		case *parser.SurfaceAreaResult:
+			inputs.SurfaceArea = data
+		case *parser.AccessibleVolumeResult:
+			inputs.AccessibleVolume = data
+		case *parser.ProbeVolumeResult:
+			inputs.ProbeVolume = data
+		case *parser.ChannelAnalysisResult:
+			inputs.Channels = data
+		}
+	}
+
+	if succeeded == 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "every analysis failed",
+			"data":    gin.H{"results": results},
+		})
+		return
+	}
+
+	response := gin.H{
+		"success": true,
+		"data": gin.H{
+			"descriptors": parser.ComputeDescriptors(inputs),
+			"results":     results,
+		},
+	}
+	if probe := probeInfo(common); probe != nil {
+		response["probe"] = probe
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type DistanceGridHandler struct {
+	*BaseHandler
+}
+
+func NewDistanceGridHandler(base *BaseHandler) *DistanceGridHandler {
+	return &DistanceGridHandler{BaseHandler: base}
+}
+
+// HandleCube returns the distance grid as a Gaussian cube file (-gridG)
+func (h *DistanceGridHandler) HandleCube(c *gin.Context) {
+	h.ProcessFileDownload(c, "grid_gaussian", h.Params(c))
+}
+
+// HandleBOV returns the distance grid as a zipped .bov header and binary
+// .distances data (-gridBOV)
+func (h *DistanceGridHandler) HandleBOV(c *gin.Context) {
+	h.ProcessFileDownload(c, "grid_bov", h.Params(c))
+}
+
+// Params extracts the distance grid parameters from the request form
+func (h *DistanceGridHandler) Params(c *gin.Context) map[string]interface{} {
+	var params = make(map[string]interface{})
+
+	// Parse form parameters
+	if ha := c.PostForm("ha"); ha == "true" {
+		params["ha"] = true
+	}
+
+	// Kept so that validation can reject it: the grid spacing is fixed
+	if spacing := c.PostForm("grid_spacing"); spacing != "" {
+		params["grid_spacing"] = spacing
+	}
+
+	return params
+}
@@ -13,6 +13,11 @@ func NewFrameworkInfoHandler(base *BaseHandler) *FrameworkInfoHandler {
 }
 
 func (h *FrameworkInfoHandler) Handle(c *gin.Context) {
+	h.ProcessAnalysis(c, "framework_info", h.Params(c))
+}
+
+// Params extracts the framework_info parameters from the request form
+func (h *FrameworkInfoHandler) Params(c *gin.Context) map[string]interface{} {
 	var params = make(map[string]interface{})
 
 	// Parse form parameters
@@ -20,5 +25,5 @@ func (h *FrameworkInfoHandler) Handle(c *gin.Context) {
 		params["ha"] = true
 	}
 
-	h.ProcessAnalysis(c, "framework_info", params)
+	return params
 }
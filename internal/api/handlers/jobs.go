@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"zeo-api/internal/core/jobs"
+	"zeo-api/internal/core/pool"
+	"zeo-api/internal/core/runner"
+	"zeo-api/internal/utils/file"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler struct {
+	*BaseHandler
+	jobs     *jobs.Manager
+	analyses map[string]func(c *gin.Context) map[string]interface{}
+}
+
+func NewJobHandler(base *BaseHandler, manager *jobs.Manager) *JobHandler {
+	return &JobHandler{
+		BaseHandler: base,
+		jobs:        manager,
+		analyses:    analysisParamParsers(base),
+	}
+}
+
+// Create queues an analysis and returns its job ID without waiting for Zeo++
+func (h *JobHandler) Create(c *gin.Context) {
+	analysisType := c.PostForm("analysis_type")
+	paramsFunc, ok := h.analyses[analysisType]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("unsupported analysis_type: %q", analysisType),
+		})
+		return
+	}
+	params := paramsFunc(c)
+	if err := h.parseCommonParams(c, params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("invalid parameters: %v", err),
+		})
+		return
+	}
+
+	// Reject invalid parameters before anything is queued
+	if _, err := runner.BuildZeoArgs(analysisType, params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("invalid parameters: %v", err),
+		})
+		return
+	}
+
+	replicates, tolerance, err := parseReplicateParams(c, analysisType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	savedPath, ok := h.saveStructureFile(c, analysisType)
+	if !ok {
+		return
+	}
+
+	var job jobs.Job
+	if replicates != 0 {
+		// Replicates fan out across the pool, so they are coordinated from
+		// outside it rather than holding a worker while they wait
+		job, err = h.jobs.Coordinate(analysisType, func(ctx context.Context, _ func(done, total int)) (interface{}, error) {
+			defer file.CleanupFile(savedPath)
+			return h.replicate(ctx, savedPath, analysisType, params, replicates, tolerance)
+		})
+	} else {
+		job, err = h.jobs.Submit(analysisType, func(ctx context.Context) (interface{}, error) {
+			defer file.CleanupFile(savedPath)
+
+			ctx, cancel := context.WithTimeout(ctx, h.config.Zeo.Timeout)
+			defer cancel()
+
+			result, _, err := h.runAnalysis(ctx, savedPath, analysisType, params)
+			return result, err
+		})
+	}
+	if err != nil {
+		file.CleanupFile(savedPath)
+		if errors.Is(err, pool.ErrQueueFull) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"success":     false,
+				"error":       "job queue is full",
+				"retry_after": "5s",
+			})
+			return
+		}
+		if errors.Is(err, jobs.ErrTooManyJobs) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"success":     false,
+				"error":       fmt.Sprintf("at most %d batch and replicate jobs can run at once", h.config.Concurrency.MaxBatchJobs),
+				"retry_after": "60s",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("failed to submit job: %v", err),
+		})
+		return
+	}
+
+	response := gin.H{
+		"success": true,
+		"data":    job,
+	}
+	if probe := probeInfo(params); probe != nil {
+		response["probe"] = probe
+	}
+	c.JSON(http.StatusAccepted, response)
+}
+
+func (h *JobHandler) Get(c *gin.Context) {
+	job, ok := h.jobs.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "job not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+func (h *JobHandler) Cancel(c *gin.Context) {
+	job, err := h.jobs.Cancel(c.Param("id"))
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "job not found",
+		})
+	case errors.Is(err, jobs.ErrAlreadyFinished):
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("job already %s", job.Status),
+			"data":    job,
+		})
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    job,
+		})
+	}
+}
+
+// Result returns the result of a completed job. Batch results can be
+// requested as JSON lines (format=jsonl) or CSV (format=csv).
+func (h *JobHandler) Result(c *gin.Context) {
+	job, ok := h.jobs.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "job not found",
+		})
+		return
+	}
+	if job.Status != jobs.StatusCompleted {
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("job is %s", job.Status),
+			"data":    job,
+		})
+		return
+	}
+
+	batch, isBatch := job.Result.(*BatchResult)
+	format := c.DefaultQuery("format", "json")
+	switch {
+	case format == "json":
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    job.Result,
+		})
+	case isBatch && format == "jsonl":
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.jsonl\"", job.ID))
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+		_ = batch.WriteJSONLines(c.Writer)
+	case isBatch && format == "csv":
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.csv\"", job.ID))
+		c.Header("Content-Type", "text/csv")
+		c.Status(http.StatusOK)
+		_ = batch.WriteCSV(c.Writer)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("unsupported format %q for this job", format),
+		})
+	}
+}
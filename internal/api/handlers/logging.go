@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"zeo-api/internal/core/metrics"
+	"zeo-api/internal/core/runner"
+)
+
+// Cache outcomes recorded for each analysis
+const (
+	cacheHit      = "hit"
+	cacheMiss     = "miss"
+	cacheBypassed = "bypassed"
+)
+
+// analysisLog collects what is known about one analysis as it runs and logs
+// and records metrics for it when the analysis ends
+type analysisLog struct {
+	analysisType  string
+	params        map[string]interface{}
+	structureHash string
+	cache         string
+	exitCode      *int
+	zeoDuration   time.Duration
+	timedOut      bool
+	start         time.Time
+}
+
+func newAnalysisLog(analysisType string, params map[string]interface{}) *analysisLog {
+	return &analysisLog{
+		analysisType: analysisType,
+		params:       params,
+		start:        time.Now(),
+	}
+}
+
+// recordRun notes the outcome of the Zeo++ execution
+func (l *analysisLog) recordRun(result *runner.ZeoResult) {
+	l.exitCode = &result.ExitCode
+	l.zeoDuration = result.Duration
+	l.timedOut = result.TimedOut
+}
+
+func (l *analysisLog) finish(err error) {
+	l.observe()
+	l.write(err)
+}
+
+func (l *analysisLog) observe() {
+	if l.cache != "" {
+		metrics.CacheLookups.WithLabelValues(l.analysisType, l.cache).Inc()
+	}
+	if l.exitCode == nil {
+		return
+	}
+	metrics.ZeoRuns.WithLabelValues(l.analysisType, strconv.Itoa(*l.exitCode)).Inc()
+	metrics.ZeoRunDuration.WithLabelValues(l.analysisType).Observe(l.zeoDuration.Seconds())
+	if l.timedOut {
+		metrics.ZeoTimeouts.WithLabelValues(l.analysisType).Inc()
+	}
+}
+
+func (l *analysisLog) write(err error) {
+	attrs := []slog.Attr{
+		slog.String("analysis_type", l.analysisType),
+		slog.Any("params", loggableParams(l.params)),
+		slog.String("structure_hash", l.structureHash),
+		slog.Float64("duration_ms", float64(time.Since(l.start))/float64(time.Millisecond)),
+	}
+	if l.cache != "" {
+		attrs = append(attrs, slog.String("cache", l.cache))
+	}
+	if l.exitCode != nil {
+		attrs = append(attrs,
+			slog.Int("exit_code", *l.exitCode),
+			slog.Float64("zeo_duration_ms", float64(l.zeoDuration)/float64(time.Millisecond)))
+	}
+	if l.timedOut {
+		attrs = append(attrs, slog.Bool("timed_out", true))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+		slog.LogAttrs(context.Background(), slog.LevelWarn, "analysis failed", attrs...)
+		return
+	}
+	slog.LogAttrs(context.Background(), slog.LevelInfo, "analysis", attrs...)
+}
+
+// loggableParams summarizes custom radii and mass tables, which can be
+// large, by their number of entries
+func loggableParams(params map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		if table, ok := value.(string); ok && (key == "radii" || key == "masses") {
+			out[key] = len(strings.Split(strings.TrimSpace(table), "\n"))
+			continue
+		}
+		out[key] = value
+	}
+	return out
+}
@@ -13,6 +13,11 @@ func NewOpenMetalSitesHandler(base *BaseHandler) *OpenMetalSitesHandler {
 }
 
 func (h *OpenMetalSitesHandler) Handle(c *gin.Context) {
+	h.ProcessAnalysis(c, "open_metal_sites", h.Params(c))
+}
+
+// Params extracts the open_metal_sites parameters from the request form
+func (h *OpenMetalSitesHandler) Params(c *gin.Context) map[string]interface{} {
 	var params = make(map[string]interface{})
 
 	// Parse form parameters
@@ -20,5 +25,5 @@ func (h *OpenMetalSitesHandler) Handle(c *gin.Context) {
 		params["ha"] = true
 	}
 
-	h.ProcessAnalysis(c, "open_metal_sites", params)
+	return params
 }
@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"zeo-api/internal/api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAtomTableSize bounds uploaded radii and mass tables
+const maxAtomTableSize = 64 << 10
+
+// parseCommonParams adds the parameters every analysis endpoint accepts to
+// params: a named probe (probe=CO2) in place of probe_radius, a named high
+// accuracy setting (ha_mode), custom atomic radii
+// (radii_file upload or radii JSON map), point atoms (point_atoms=true) and
+// custom masses (mass_file upload or masses JSON map). Tables are normalized
+// to Zeo++'s "Element value" text format.
+func (h *BaseHandler) parseCommonParams(c *gin.Context, params map[string]interface{}) error {
+	if probe := c.PostForm("probe"); probe != "" {
+		if c.PostForm("probe_radius") != "" {
+			return fmt.Errorf("probe and probe_radius cannot both be given")
+		}
+		name, radius, err := h.resolveProbe(probe)
+		if err != nil {
+			return err
+		}
+		params["probe"] = name
+		params["probe_radius"] = radius
+	}
+
+	if haMode := c.PostForm("ha_mode"); haMode != "" {
+		params["ha_mode"] = strings.ToUpper(strings.TrimSpace(haMode))
+	}
+
+	radii, err := atomTableParam(c, "radii_file", "radii")
+	if err != nil {
+		return err
+	}
+	if radii != "" {
+		params["radii"] = radii
+	}
+
+	if pointAtoms := c.PostForm("point_atoms"); pointAtoms == "true" {
+		params["point_atoms"] = true
+	}
+
+	masses, err := atomTableParam(c, "mass_file", "masses")
+	if err != nil {
+		return err
+	}
+	if masses != "" {
+		params["masses"] = masses
+	}
+
+	return nil
+}
+
+// atomTableParam reads an element→value table from either an uploaded file
+// or a JSON object form field, returning "" when neither is given
+func atomTableParam(c *gin.Context, fileField, jsonField string) (string, error) {
+	table := make(map[string]float64)
+
+	if fileHeader, err := c.FormFile(fileField); err == nil {
+		f, err := fileHeader.Open()
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", fileField, err)
+		}
+		defer f.Close()
+
+		content, err := io.ReadAll(io.LimitReader(f, maxAtomTableSize+1))
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", fileField, err)
+		}
+		if len(content) > maxAtomTableSize {
+			return "", fmt.Errorf("%s exceeds %d bytes", fileField, maxAtomTableSize)
+		}
+		for i, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			parts := strings.Fields(line)
+			if len(parts) != 2 {
+				return "", fmt.Errorf("%s line %d: expected \"Element value\"", fileField, i+1)
+			}
+			value, err := strconv.ParseFloat(parts[1], 64)
+			if err != nil {
+				return "", fmt.Errorf("%s line %d: invalid value %q", fileField, i+1, parts[1])
+			}
+			table[parts[0]] = value
+		}
+	} else if raw := c.PostForm(jsonField); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &table); err != nil {
+			return "", fmt.Errorf("%s must be a JSON object of element to value: %w", jsonField, err)
+		}
+	} else {
+		return "", nil
+	}
+
+	if len(table) == 0 {
+		return "", fmt.Errorf("%s is empty", jsonField)
+	}
+
+	elements := make([]string, 0, len(table))
+	for element, value := range table {
+		if !isElementSymbol(element) {
+			return "", fmt.Errorf("%s: invalid element symbol %q", jsonField, element)
+		}
+		if value < 0 || value > 1000 {
+			return "", fmt.Errorf("%s: value for %s must be between 0 and 1000", jsonField, element)
+		}
+		elements = append(elements, element)
+	}
+	sort.Strings(elements)
+
+	var b strings.Builder
+	for _, element := range elements {
+		fmt.Fprintf(&b, "%s %s\n", element, strconv.FormatFloat(table[element], 'f', -1, 64))
+	}
+	return b.String(), nil
+}
+
+// isElementSymbol accepts one capital letter optionally followed by lower
+// case letters, which also covers Zeo++ atom type labels such as "Ow"
+func isElementSymbol(s string) bool {
+	if s == "" || len(s) > 3 || s[0] < 'A' || s[0] > 'Z' {
+		return false
+	}
+	for _, r := range s[1:] {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseAnalysisSpecs reads an "analyses" field: either a JSON object mapping
+// analysis type to its parameter object, or a comma-separated list of types
+// to run with default parameters. A named probe in a parameter object is
+// resolved to its radius. Atom tables can only be given once per request,
+// through the regular form fields.
+func (h *BaseHandler) parseAnalysisSpecs(raw string, supported map[string]func(c *gin.Context) map[string]interface{}) (map[string]map[string]interface{}, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("analyses is required")
+	}
+
+	specs := make(map[string]map[string]interface{})
+	if strings.HasPrefix(raw, "{") {
+		if err := json.Unmarshal([]byte(raw), &specs); err != nil {
+			return nil, fmt.Errorf("analyses must be a JSON object of analysis type to parameters: %w", err)
+		}
+	} else {
+		for _, analysisType := range strings.Split(raw, ",") {
+			if analysisType = strings.TrimSpace(analysisType); analysisType != "" {
+				specs[analysisType] = map[string]interface{}{}
+			}
+		}
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("analyses is required")
+	}
+
+	for analysisType, params := range specs {
+		if _, ok := supported[analysisType]; !ok {
+			return nil, fmt.Errorf("unsupported analysis type: %s", analysisType)
+		}
+		if params == nil {
+			specs[analysisType] = map[string]interface{}{}
+			continue
+		}
+		for _, key := range []string{"radii", "masses"} {
+			if _, ok := params[key]; ok {
+				return nil, fmt.Errorf("%s: %s must be given as a request field, not per analysis", analysisType, key)
+			}
+		}
+		if err := normalizeSpecParams(params); err != nil {
+			return nil, fmt.Errorf("%s: %w", analysisType, err)
+		}
+		if mode, ok := params["ha_mode"].(string); ok {
+			params["ha_mode"] = strings.ToUpper(strings.TrimSpace(mode))
+		}
+		if probe, ok := params["probe"].(string); ok {
+			if _, ok := params["probe_radius"]; ok {
+				return nil, fmt.Errorf("%s: probe and probe_radius cannot both be given", analysisType)
+			}
+			name, radius, err := h.resolveProbe(probe)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", analysisType, err)
+			}
+			params["probe"] = name
+			params["probe_radius"] = radius
+		}
+	}
+
+	return specs, nil
+}
+
+// specParamKinds lists the parameters an "analyses" entry may set and the
+// type each must have, which is the type the form parsers produce
+var specParamKinds = map[string]reflect.Kind{
+	"ha":           reflect.Bool,
+	"ha_mode":      reflect.String,
+	"point_atoms":  reflect.Bool,
+	"probe":        reflect.String,
+	"probe_radius": reflect.Float64,
+	"chan_radius":  reflect.Float64,
+	"samples":      reflect.Int,
+	"bins":         reflect.Int,
+}
+
+// normalizeSpecParams converts the JSON values of an "analyses" entry to the
+// types the runner expects. Numbers and booleans may also be given as
+// strings; anything else of the wrong type, or an unknown key, is an error
+// rather than being silently replaced by the default.
+func normalizeSpecParams(params map[string]interface{}) error {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		kind, ok := specParamKinds[key]
+		if !ok {
+			return fmt.Errorf("unknown parameter %s", key)
+		}
+		value := params[key]
+		raw, isString := value.(string)
+		raw = strings.TrimSpace(raw)
+
+		switch kind {
+		case reflect.Float64:
+			if isString {
+				v, err := strconv.ParseFloat(raw, 64)
+				if err != nil {
+					return fmt.Errorf("%s must be a number, got %q", key, raw)
+				}
+				value = v
+			}
+			if _, ok := value.(float64); !ok {
+				return fmt.Errorf("%s must be a number", key)
+			}
+		case reflect.Int:
+			if isString {
+				v, err := strconv.Atoi(raw)
+				if err != nil {
+					return fmt.Errorf("%s must be an integer, got %q", key, raw)
+				}
+				value = v
+			}
+			if v, ok := value.(float64); ok {
+				if v != math.Trunc(v) || math.Abs(v) > math.MaxInt32 {
+					return fmt.Errorf("%s must be an integer, got %g", key, v)
+				}
+				value = int(v)
+			}
+			if _, ok := value.(int); !ok {
+				return fmt.Errorf("%s must be an integer", key)
+			}
+		case reflect.Bool:
+			if isString {
+				v, err := strconv.ParseBool(raw)
+				if err != nil {
+					return fmt.Errorf("%s must be true or false, got %q", key, raw)
+				}
+				value = v
+			}
+			if _, ok := value.(bool); !ok {
+				return fmt.Errorf("%s must be true or false", key)
+			}
+		case reflect.String:
+			if !isString {
+				return fmt.Errorf("%s must be a string", key)
+			}
+		}
+		params[key] = value
+	}
+	return nil
+}
+
+// resolveProbe looks up a named probe from the configured table
+func (h *BaseHandler) resolveProbe(probe string) (string, float64, error) {
+	name, radius, ok := h.config.ProbeRadius(probe)
+	if !ok {
+		known := make([]string, 0, len(h.config.Probes))
+		for name := range h.config.Probes {
+			known = append(known, name)
+		}
+		sort.Strings(known)
+		return "", 0, fmt.Errorf("unknown probe %q. Known probes: %s", probe, strings.Join(known, ", "))
+	}
+	return name, radius, nil
+}
+
+// probeInfo reports the named probe a request resolved, if any, so responses
+// can echo the radius actually used
+func probeInfo(params map[string]interface{}) *models.ProbeInfo {
+	name, ok := params["probe"].(string)
+	if !ok {
+		return nil
+	}
+	radius, _ := params["probe_radius"].(float64)
+	return &models.ProbeInfo{Name: name, Radius: radius}
+}
+
+// mergeParams layers per-analysis parameters over request-wide ones. A
+// per-analysis probe_radius also replaces a request-wide named probe.
+func mergeParams(common, specific map[string]interface{}) map[string]interface{} {
+	params := make(map[string]interface{}, len(common)+len(specific))
+	for key, value := range common {
+		params[key] = value
+	}
+	if _, ok := specific["probe_radius"]; ok {
+		delete(params, "probe")
+	}
+	for key, value := range specific {
+		params[key] = value
+	}
+	return params
+}
@@ -13,6 +13,11 @@ func NewPoreDiameterHandler(base *BaseHandler) *PoreDiameterHandler {
 }
 
 func (h *PoreDiameterHandler) Handle(c *gin.Context) {
+	h.ProcessAnalysis(c, "pore_diameter", h.Params(c))
+}
+
+// Params extracts the pore_diameter parameters from the request form
+func (h *PoreDiameterHandler) Params(c *gin.Context) map[string]interface{} {
 	var params = make(map[string]interface{})
 
 	// Parse form parameters
@@ -20,5 +25,5 @@ func (h *PoreDiameterHandler) Handle(c *gin.Context) {
 		params["ha"] = true
 	}
 
-	h.ProcessAnalysis(c, "pore_diameter", params)
+	return params
 }
@@ -15,6 +15,16 @@ func NewPoreSizeDistHandler(base *BaseHandler) *PoreSizeDistHandler {
 }
 
 func (h *PoreSizeDistHandler) Handle(c *gin.Context) {
+	h.ProcessAnalysis(c, "pore_size_dist", h.Params(c))
+}
+
+// Download streams the raw Zeo++ .psd_histo histogram instead of parsed JSON
+func (h *PoreSizeDistHandler) Download(c *gin.Context) {
+	h.ProcessFileDownload(c, "pore_size_dist", h.Params(c))
+}
+
+// Params extracts the pore_size_dist parameters from the request form
+func (h *PoreSizeDistHandler) Params(c *gin.Context) map[string]interface{} {
 	var params = make(map[string]interface{})
 
 	// Parse form parameters
@@ -42,5 +52,14 @@ func (h *PoreSizeDistHandler) Handle(c *gin.Context) {
 		params["samples"] = 50000
 	}
 
-	h.ProcessFileDownload(c, "pore_size_dist", params)
+	// An unparseable value is kept as is so that validation rejects it
+	if bins := c.PostForm("bins"); bins != "" {
+		if val, err := strconv.Atoi(bins); err == nil {
+			params["bins"] = val
+		} else {
+			params["bins"] = bins
+		}
+	}
+
+	return params
 }
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"zeo-api/internal/api/models"
+	"zeo-api/internal/utils/file"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxSweepPoints bounds the number of probe radii in one sweep
+	maxSweepPoints = 100
+
+	defaultSweepAnalyses = "surface_area,probe_volume,channel_analysis"
+)
+
+type ProbeSweepHandler struct {
+	*BaseHandler
+	analyses map[string]func(c *gin.Context) map[string]interface{}
+}
+
+func NewProbeSweepHandler(base *BaseHandler) *ProbeSweepHandler {
+	// Only analyses that take a probe radius can be swept
+	all := analysisParamParsers(base)
+	analyses := make(map[string]func(c *gin.Context) map[string]interface{})
+	for _, analysisType := range []string{"surface_area", "accessible_volume", "probe_volume", "channel_analysis"} {
+		analyses[analysisType] = all[analysisType]
+	}
+
+	return &ProbeSweepHandler{
+		BaseHandler: base,
+		analyses:    analyses,
+	}
+}
+
+// SweepPoint holds the results of every swept analysis at one probe radius
+type SweepPoint struct {
+	Probe       string                        `json:"probe,omitempty"`
+	ProbeRadius float64                       `json:"probe_radius"`
+	Results     map[string]models.APIResponse `json:"results"`
+}
+
+// Handle runs the requested analyses over a range of probe radii on one
+// uploaded structure. Radii are given either as an explicit probe_radii list,
+// which may mix numbers and named probes, or as start/stop/step. Unless set
+// explicitly, chan_radius follows the probe radius so that accessibility is
+// judged for the probe being swept.
+func (h *ProbeSweepHandler) Handle(c *gin.Context) {
+	if c.PostForm("probe") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "probe cannot be combined with a sweep; list named probes in probe_radii",
+		})
+		return
+	}
+
+	points, err := h.parseSweepPoints(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	specs, err := h.parseAnalysisSpecs(c.DefaultPostForm("analyses", defaultSweepAnalyses), h.analyses)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	for analysisType, params := range specs {
+		// A named probe in the analysis has been resolved to probe_radius
+		if _, ok := params["probe_radius"]; ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("%s: probe_radius is set by the sweep", analysisType),
+			})
+			return
+		}
+	}
+
+	common := make(map[string]interface{})
+	if err := h.parseCommonParams(c, common); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("invalid parameters: %v", err),
+		})
+		return
+	}
+
+	savedPath, ok := h.saveStructureFile(c, "probe_sweep")
+	if !ok {
+		return
+	}
+	defer file.CleanupFile(savedPath)
+
+	radii := make([]float64, len(points))
+	for i, point := range points {
+		radii[i] = point.ProbeRadius
+	}
+	h.sweep(c.Request.Context(), savedPath, points, specs, common)
+	if respondInterrupted(c) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"probe_radii": radii,
+			"points":      points,
+		},
+	})
+}
+
+// sweep runs every analysis at every point concurrently and fills in each
+// point's results
+func (h *ProbeSweepHandler) sweep(ctx context.Context, savedPath string, points []SweepPoint, specs map[string]map[string]interface{}, common map[string]interface{}) {
+	analysisTypes := sortedKeys(specs)
+	runs := make([]analysisRun, 0, len(points)*len(analysisTypes))
+	for _, point := range points {
+		for _, analysisType := range analysisTypes {
+			params := mergeParams(common, specs[analysisType])
+			params["probe_radius"] = point.ProbeRadius
+			if _, ok := params["chan_radius"]; !ok {
+				params["chan_radius"] = point.ProbeRadius
+			}
+			runs = append(runs, analysisRun{analysisType: analysisType, params: params})
+		}
+	}
+
+	outcomes := h.runConcurrently(ctx, savedPath, runs)
+	for i := range points {
+		points[i].Results = make(map[string]models.APIResponse, len(analysisTypes))
+		for j, analysisType := range analysisTypes {
+			points[i].Results[analysisType] = outcomes[i*len(analysisTypes)+j]
+		}
+	}
+}
+
+func (h *ProbeSweepHandler) parseSweepPoints(c *gin.Context) ([]SweepPoint, error) {
+	var points []SweepPoint
+
+	if list := strings.TrimSpace(c.PostForm("probe_radii")); list != "" {
+		for _, field := range strings.Split(list, ",") {
+			field = strings.TrimSpace(field)
+			if radius, err := strconv.ParseFloat(field, 64); err == nil {
+				points = append(points, SweepPoint{ProbeRadius: radius})
+				continue
+			}
+			name, radius, err := h.resolveProbe(field)
+			if err != nil {
+				return nil, err
+			}
+			points = append(points, SweepPoint{Probe: name, ProbeRadius: radius})
+		}
+	} else {
+		start, errStart := strconv.ParseFloat(c.PostForm("start"), 64)
+		stop, errStop := strconv.ParseFloat(c.PostForm("stop"), 64)
+		step, errStep := strconv.ParseFloat(c.PostForm("step"), 64)
+		if errStart != nil || errStop != nil || errStep != nil {
+			return nil, fmt.Errorf("either probe_radii or numeric start, stop and step are required")
+		}
+		if step <= 0 || stop < start {
+			return nil, fmt.Errorf("step must be positive and stop must not be less than start")
+		}
+		if (stop-start)/step >= maxSweepPoints {
+			return nil, fmt.Errorf("sweep has more than %d points", maxSweepPoints)
+		}
+		// Radii are computed from the index rather than accumulated, and
+		// rounded, so that 0.1 steps do not drift or miss the stop value
+		for i := 0; ; i++ {
+			radius := math.Round((start+float64(i)*step)*1e6) / 1e6
+			if radius > stop+1e-9 {
+				break
+			}
+			points = append(points, SweepPoint{ProbeRadius: radius})
+		}
+	}
+
+	if len(points) == 0 {
+		return nil, fmt.Errorf("no probe radii given")
+	}
+	if len(points) > maxSweepPoints {
+		return nil, fmt.Errorf("sweep has more than %d points", maxSweepPoints)
+	}
+	for _, point := range points {
+		if point.ProbeRadius < 0.1 || point.ProbeRadius > 10.0 {
+			return nil, fmt.Errorf("probe radius %g must be between 0.1 and 10.0", point.ProbeRadius)
+		}
+	}
+	return points, nil
+}
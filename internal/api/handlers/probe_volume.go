@@ -15,6 +15,11 @@ func NewProbeVolumeHandler(base *BaseHandler) *ProbeVolumeHandler {
 }
 
 func (h *ProbeVolumeHandler) Handle(c *gin.Context) {
+	h.ProcessAnalysis(c, "probe_volume", h.Params(c))
+}
+
+// Params extracts the probe_volume parameters from the request form
+func (h *ProbeVolumeHandler) Params(c *gin.Context) map[string]interface{} {
 	var params = make(map[string]interface{})
 
 	// Parse form parameters
@@ -42,5 +47,5 @@ func (h *ProbeVolumeHandler) Handle(c *gin.Context) {
 		params["samples"] = 2000
 	}
 
-	h.ProcessAnalysis(c, "probe_volume", params)
+	return params
 }
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"zeo-api/internal/api/models"
+	"zeo-api/internal/utils/file"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProfileHandler struct {
+	*BaseHandler
+	analyses map[string]func(c *gin.Context) map[string]interface{}
+}
+
+func NewProfileHandler(base *BaseHandler) *ProfileHandler {
+	return &ProfileHandler{
+		BaseHandler: base,
+		analyses:    analysisParamParsers(base),
+	}
+}
+
+// Handle runs several analyses on one uploaded structure and returns their
+// results keyed by analysis type. A failing analysis is reported in its own
+// entry and does not fail the profile.
+func (h *ProfileHandler) Handle(c *gin.Context) {
+	specs, err := h.parseAnalysisSpecs(c.PostForm("analyses"), h.analyses)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	common := make(map[string]interface{})
+	if err := h.parseCommonParams(c, common); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("invalid parameters: %v", err),
+		})
+		return
+	}
+
+	savedPath, ok := h.saveStructureFile(c, "profile")
+	if !ok {
+		return
+	}
+	defer file.CleanupFile(savedPath)
+
+	results := h.runAll(c.Request.Context(), savedPath, specs, common)
+	if respondInterrupted(c) {
+		return
+	}
+
+	response := gin.H{
+		"success": true,
+		"data":    results,
+	}
+	if probe := probeInfo(common); probe != nil {
+		response["probe"] = probe
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// runAll runs each analysis on the saved structure and keys the outcomes by
+// analysis type
+func (h *ProfileHandler) runAll(ctx context.Context, savedPath string, specs map[string]map[string]interface{}, common map[string]interface{}) map[string]models.APIResponse {
+	runs := make([]analysisRun, 0, len(specs))
+	for analysisType, specific := range specs {
+		runs = append(runs, analysisRun{analysisType: analysisType, params: mergeParams(common, specific)})
+	}
+
+	outcomes := h.runConcurrently(ctx, savedPath, runs)
+	results := make(map[string]models.APIResponse, len(runs))
+	for i, run := range runs {
+		results[run.analysisType] = outcomes[i]
+	}
+	return results
+}
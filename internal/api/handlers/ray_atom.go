@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RayAtomHandler struct {
+	*BaseHandler
+}
+
+func NewRayAtomHandler(base *BaseHandler) *RayAtomHandler {
+	return &RayAtomHandler{BaseHandler: base}
+}
+
+func (h *RayAtomHandler) Handle(c *gin.Context) {
+	h.ProcessAnalysis(c, "ray_atom", h.Params(c))
+}
+
+// Params extracts the ray_atom parameters from the request form
+func (h *RayAtomHandler) Params(c *gin.Context) map[string]interface{} {
+	var params = make(map[string]interface{})
+
+	// Parse form parameters
+	if ha := c.PostForm("ha"); ha == "true" {
+		params["ha"] = true
+	}
+
+	if probeRadius := c.PostForm("probe_radius"); probeRadius != "" {
+		if val, err := strconv.ParseFloat(probeRadius, 64); err == nil {
+			params["probe_radius"] = val
+		}
+	}
+
+	if chanRadius := c.PostForm("chan_radius"); chanRadius != "" {
+		if val, err := strconv.ParseFloat(chanRadius, 64); err == nil {
+			params["chan_radius"] = val
+		}
+	}
+
+	if samples := c.PostForm("samples"); samples != "" {
+		if val, err := strconv.Atoi(samples); err == nil {
+			params["samples"] = val
+		}
+	} else {
+		params["samples"] = 50000
+	}
+
+	return params
+}
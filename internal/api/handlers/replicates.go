@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"zeo-api/internal/api/models"
+	"zeo-api/internal/core/runner"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxReplicates = 20
+
+	// defaultTolerance is the relative standard error of the mean below
+	// which a metric counts as converged
+	defaultTolerance = 0.01
+)
+
+// monteCarloAnalyses are the analyses whose results are sampling estimates
+var monteCarloAnalyses = map[string]bool{
+	"surface_area":      true,
+	"accessible_volume": true,
+	"probe_volume":      true,
+	"pore_size_dist":    true,
+}
+
+// parseReplicateParams reads the optional replicates count and convergence
+// tolerance. A replicates count of zero means a single, cacheable run.
+func parseReplicateParams(c *gin.Context, analysisType string) (int, float64, error) {
+	raw := c.PostForm("replicates")
+	if raw == "" {
+		return 0, 0, nil
+	}
+	if !monteCarloAnalyses[analysisType] {
+		return 0, 0, fmt.Errorf("replicates is only supported for surface_area, accessible_volume, probe_volume and pore_size_dist")
+	}
+
+	replicates, err := strconv.Atoi(raw)
+	if err != nil || replicates < 2 || replicates > maxReplicates {
+		return 0, 0, fmt.Errorf("replicates must be an integer between 2 and %d", maxReplicates)
+	}
+
+	tolerance := defaultTolerance
+	if raw := c.PostForm("tolerance"); raw != "" {
+		tolerance, err = strconv.ParseFloat(raw, 64)
+		if err != nil || tolerance <= 0 || tolerance > 1 {
+			return 0, 0, fmt.Errorf("tolerance must be a number in (0, 1]")
+		}
+	}
+
+	return replicates, tolerance, nil
+}
+
+// respondReplicates runs a Monte Carlo analysis several times, bypassing the
+// cache, and responds with per-metric statistics.
+//
+// Zeo++ has no option to set its random seed, so replicates are plain
+// repeated runs and rely on Zeo++ drawing different samples each time. When
+// every run returns the same numbers the spread says nothing about sampling
+// error, and the summary carries a warning instead of claiming convergence.
+func (h *BaseHandler) respondReplicates(c *gin.Context, savedPath string, analysisType string, params map[string]interface{}, replicates int, tolerance float64) {
+	if _, err := runner.BuildZeoArgs(analysisType, params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("invalid parameters: %v", err),
+		})
+		return
+	}
+
+	summary, err := h.replicate(c.Request.Context(), savedPath, analysisType, params, replicates, tolerance)
+	if respondInterrupted(c) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	response := gin.H{
+		"success": true,
+		"data":    summary,
+		"cached":  false,
+	}
+	if probe := probeInfo(params); probe != nil {
+		response["probe"] = probe
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// replicate runs the analysis replicates times across the worker pool and
+// summarizes the results
+func (h *BaseHandler) replicate(ctx context.Context, savedPath string, analysisType string, params map[string]interface{}, replicates int, tolerance float64) (*models.ReplicateSummary, error) {
+	runs := make([]analysisRun, replicates)
+	for i := range runs {
+		runs[i] = analysisRun{analysisType: analysisType, params: params, noCache: true}
+	}
+
+	outcomes := h.runConcurrently(ctx, savedPath, runs)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	results := make([]interface{}, len(outcomes))
+	for i, outcome := range outcomes {
+		if !outcome.Success {
+			return nil, fmt.Errorf("replicate %d failed: %s", i+1, outcome.Error)
+		}
+		results[i] = outcome.Data
+	}
+
+	summary, err := summarizeReplicates(results, tolerance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize replicates: %w", err)
+	}
+	return summary, nil
+}
+
+// summarizeReplicates computes the mean, sample standard deviation and
+// relative standard error of every numeric value present in all results.
+// Overall convergence only considers scalar metrics, since sparsely
+// populated histogram bins converge far more slowly than the totals.
+func summarizeReplicates(results []interface{}, tolerance float64) (*models.ReplicateSummary, error) {
+	flat := make([]map[string]float64, len(results))
+	for i, result := range results {
+		values, err := flattenResult("", result, true)
+		if err != nil {
+			return nil, err
+		}
+		flat[i] = make(map[string]float64)
+		for key, value := range values {
+			if number, ok := value.(float64); ok {
+				flat[i][key] = number
+			}
+		}
+	}
+
+	summary := &models.ReplicateSummary{
+		Replicates: len(results),
+		Tolerance:  tolerance,
+		Converged:  true,
+		Metrics:    make(map[string]models.MetricSummary),
+		Runs:       results,
+	}
+
+	n := float64(len(results))
+	identical := true
+	for key := range flat[0] {
+		values := make([]float64, 0, len(flat))
+		for _, run := range flat {
+			if value, ok := run[key]; ok {
+				values = append(values, value)
+			}
+		}
+		if len(values) != len(flat) {
+			continue
+		}
+
+		var sum, sumAbs float64
+		for _, value := range values {
+			sum += value
+			sumAbs += math.Abs(value)
+		}
+		mean := sum / n
+		var squares float64
+		for _, value := range values {
+			squares += (value - mean) * (value - mean)
+		}
+		std := math.Sqrt(squares / (n - 1))
+
+		// Relative to the mean magnitude so that all-zero metrics are exact
+		// rather than undefined
+		metric := models.MetricSummary{Mean: mean, Std: std, Converged: true}
+		if sumAbs > 0 {
+			metric.RelativeError = std / math.Sqrt(n) / (sumAbs / n)
+			metric.Converged = metric.RelativeError <= tolerance
+		}
+		summary.Metrics[key] = metric
+
+		if std > 0 {
+			identical = false
+		}
+		if !metric.Converged && !strings.Contains(key, "[") {
+			summary.Converged = false
+		}
+	}
+
+	if identical {
+		summary.Converged = false
+		summary.Warning = "all replicates returned identical results; Zeo++ may be sampling with a fixed seed, so the spread does not measure sampling error"
+	}
+	return summary, nil
+}
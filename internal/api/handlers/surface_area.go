@@ -15,6 +15,11 @@ func NewSurfaceAreaHandler(base *BaseHandler) *SurfaceAreaHandler {
 }
 
 func (h *SurfaceAreaHandler) Handle(c *gin.Context) {
+	h.ProcessAnalysis(c, "surface_area", h.Params(c))
+}
+
+// Params extracts the surface_area parameters from the request form
+func (h *SurfaceAreaHandler) Params(c *gin.Context) map[string]interface{} {
 	var params = make(map[string]interface{})
 
 	// Parse form parameters
@@ -36,5 +41,5 @@ func (h *SurfaceAreaHandler) Handle(c *gin.Context) {
 		params["samples"] = 2000
 	}
 
-	h.ProcessAnalysis(c, "surface_area", params)
+	return params
 }
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type VoronoiNetworkHandler struct {
+	*BaseHandler
+}
+
+func NewVoronoiNetworkHandler(base *BaseHandler) *VoronoiNetworkHandler {
+	return &VoronoiNetworkHandler{BaseHandler: base}
+}
+
+func (h *VoronoiNetworkHandler) Handle(c *gin.Context) {
+	h.ProcessAnalysis(c, "voronoi_network", h.Params(c))
+}
+
+// Download streams the raw Zeo++ .nt2 network instead of parsed JSON
+func (h *VoronoiNetworkHandler) Download(c *gin.Context) {
+	h.ProcessFileDownload(c, "voronoi_network", h.Params(c))
+}
+
+// Params extracts the voronoi_network parameters from the request form
+func (h *VoronoiNetworkHandler) Params(c *gin.Context) map[string]interface{} {
+	var params = make(map[string]interface{})
+
+	// Parse form parameters
+	if ha := c.PostForm("ha"); ha == "true" {
+		params["ha"] = true
+	}
+
+	return params
+}
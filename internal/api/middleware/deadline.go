@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResponseDeadline gives the request context a deadline, set shortly before
+// the server's write timeout, so that handlers running Zeo++ stop in time to
+// send an error instead of having the connection cut under them. The
+// context is also cancelled when the client disconnects.
+func ResponseDeadline(timeout time.Duration) gin.HandlerFunc {
+	if timeout <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -14,6 +15,7 @@ type RateLimiter struct {
 	mu       sync.RWMutex
 	rate     rate.Limit
 	burst    int
+	rejected atomic.Int64
 }
 
 func NewRateLimiter(r rate.Limit, burst int) *RateLimiter {
@@ -45,6 +47,7 @@ func (rl *RateLimiter) RateLimit() gin.HandlerFunc {
 		limiter := rl.getLimiter(ip)
 
 		if !limiter.Allow() {
+			rl.rejected.Add(1)
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error":       "Rate limit exceeded",
 				"retry_after": "1s",
@@ -57,6 +60,12 @@ func (rl *RateLimiter) RateLimit() gin.HandlerFunc {
 	}
 }
 
+// Rejected returns how many requests have been refused for exceeding their
+// per-IP rate
+func (rl *RateLimiter) Rejected() int64 {
+	return rl.rejected.Load()
+}
+
 func NewGlobalSemaphore(maxConcurrent int) *GlobalSemaphore {
 	return &GlobalSemaphore{
 		sem: make(chan struct{}, maxConcurrent),
@@ -64,7 +73,8 @@ func NewGlobalSemaphore(maxConcurrent int) *GlobalSemaphore {
 }
 
 type GlobalSemaphore struct {
-	sem chan struct{}
+	sem      chan struct{}
+	rejected atomic.Int64
 }
 
 func (gs *GlobalSemaphore) Acquire() bool {
@@ -80,9 +90,25 @@ func (gs *GlobalSemaphore) Release() {
 	<-gs.sem
 }
 
+// InUse returns the number of slots currently held
+func (gs *GlobalSemaphore) InUse() int {
+	return len(gs.sem)
+}
+
+func (gs *GlobalSemaphore) Capacity() int {
+	return cap(gs.sem)
+}
+
+// Rejected returns how many requests have been refused because every slot
+// was taken
+func (gs *GlobalSemaphore) Rejected() int64 {
+	return gs.rejected.Load()
+}
+
 func (gs *GlobalSemaphore) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if !gs.Acquire() {
+			gs.rejected.Add(1)
 			c.JSON(http.StatusServiceUnavailable, gin.H{
 				"error":       "Server overloaded",
 				"retry_after": "5s",
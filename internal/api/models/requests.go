@@ -10,7 +10,7 @@ type AnalysisRequest struct {
 	ProbeRadius   float64               `form:"probe_radius" binding:"omitempty,min=0.1,max=10"`
 	ChanRadius    float64               `form:"chan_radius" binding:"omitempty,min=0.1,max=10"`
 	Samples       int                   `form:"samples" binding:"omitempty,min=100,max=1000000"`
-	Bins          int                   `form:"bins" binding:"omitempty,min=10,max=1000"`
+	Bins          int                   `form:"bins" binding:"omitempty,min=1,max=1000"`
 }
 
 type PoreDiameterRequest struct {
@@ -45,6 +45,7 @@ type PSDRequest struct {
 	ProbeRadius   float64               `form:"probe_radius" binding:"omitempty,min=0.1,max=10"`
 	ChanRadius    float64               `form:"chan_radius" binding:"omitempty,min=0.1,max=10"`
 	Samples       int                   `form:"samples" binding:"omitempty,min=1000,max=1000000"`
+	Bins          int                   `form:"bins" binding:"omitempty,min=1,max=1000"`
 }
 
 type BlockingSpheresRequest struct {
@@ -5,6 +5,35 @@ type APIResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
 	Cached  bool        `json:"cached"`
+	// Probe is set on the per-analysis entries of multi-analysis responses
+	// whose analysis ran with a named probe
+	Probe *ProbeInfo `json:"probe,omitempty"`
+}
+
+// ProbeInfo echoes a named probe and the radius it resolved to
+type ProbeInfo struct {
+	Name   string  `json:"name"`
+	Radius float64 `json:"radius"`
+}
+
+// MetricSummary describes one numeric result across replicate runs
+type MetricSummary struct {
+	Mean          float64 `json:"mean"`
+	Std           float64 `json:"std"`
+	RelativeError float64 `json:"relative_error"`
+	Converged     bool    `json:"converged"`
+}
+
+// ReplicateSummary aggregates independent runs of a Monte Carlo analysis.
+// Metrics are keyed by their path in the single-run result, e.g. "asa_mass"
+// or "counts[3]".
+type ReplicateSummary struct {
+	Replicates int                      `json:"replicates"`
+	Tolerance  float64                  `json:"tolerance"`
+	Converged  bool                     `json:"converged"`
+	Warning    string                   `json:"warning,omitempty"`
+	Metrics    map[string]MetricSummary `json:"metrics"`
+	Runs       []interface{}            `json:"runs"`
 }
 
 type PoreDiameterResponse struct {
@@ -15,31 +44,39 @@ type PoreDiameterResponse struct {
 }
 
 type SurfaceAreaResponse struct {
-	ASAUnitcell  float64 `json:"asa_unitcell"`
-	ASAVolume    float64 `json:"asa_volume"`
-	ASAMass      float64 `json:"asa_mass"`
-	NASAUnitcell float64 `json:"nasa_unitcell"`
-	NASAVolume   float64 `json:"nasa_volume"`
-	NASAMass     float64 `json:"nasa_mass"`
-	Cached       bool    `json:"cached"`
+	UnitcellVolume float64 `json:"unitcell_volume,omitempty"`
+	Density        float64 `json:"density,omitempty"`
+	ASAUnitcell    float64 `json:"asa_unitcell"`
+	ASAVolume      float64 `json:"asa_volume"`
+	ASAMass        float64 `json:"asa_mass"`
+	NASAUnitcell   float64 `json:"nasa_unitcell"`
+	NASAVolume     float64 `json:"nasa_volume"`
+	NASAMass       float64 `json:"nasa_mass"`
+	Cached         bool    `json:"cached"`
 }
 
 type AccessibleVolumeResponse struct {
-	UnitcellVolume float64            `json:"unitcell_volume"`
-	Density        float64            `json:"density"`
-	AV             map[string]float64 `json:"av"`
-	NAV            map[string]float64 `json:"nav"`
-	Cached         bool               `json:"cached"`
+	UnitcellVolume   float64            `json:"unitcell_volume"`
+	Density          float64            `json:"density"`
+	AV               map[string]float64 `json:"av"`
+	NAV              map[string]float64 `json:"nav"`
+	NumberOfChannels int                `json:"number_of_channels"`
+	ChannelVolumes   []float64          `json:"channel_volumes"`
+	NumberOfPockets  int                `json:"number_of_pockets"`
+	PocketVolumes    []float64          `json:"pocket_volumes"`
+	Cached           bool               `json:"cached"`
 }
 
 type ProbeVolumeResponse struct {
-	POAVUnitcell  float64 `json:"poav_unitcell"`
-	POAVFraction  float64 `json:"poav_fraction"`
-	POAVMass      float64 `json:"poav_mass"`
-	PONAVUnitcell float64 `json:"ponav_unitcell"`
-	PONAVFraction float64 `json:"ponav_fraction"`
-	PONAVMass     float64 `json:"ponav_mass"`
-	Cached        bool    `json:"cached"`
+	UnitcellVolume float64 `json:"unitcell_volume,omitempty"`
+	Density        float64 `json:"density,omitempty"`
+	POAVUnitcell   float64 `json:"poav_unitcell"`
+	POAVFraction   float64 `json:"poav_fraction"`
+	POAVMass       float64 `json:"poav_mass"`
+	PONAVUnitcell  float64 `json:"ponav_unitcell"`
+	PONAVFraction  float64 `json:"ponav_fraction"`
+	PONAVMass      float64 `json:"ponav_mass"`
+	Cached         bool    `json:"cached"`
 }
 
 type ChannelAnalysisResponse struct {
@@ -50,22 +87,38 @@ type ChannelAnalysisResponse struct {
 	Cached            bool    `json:"cached"`
 }
 
+type StructureComponent struct {
+	Index          int    `json:"index"`
+	Dimensionality int    `json:"dimensionality"`
+	Atoms          int    `json:"atoms"`
+	Formula        string `json:"formula"`
+}
+
 type FrameworkInfoResponse struct {
-	Filename           string                   `json:"filename"`
-	Formula            string                   `json:"formula"`
-	Segments           int                      `json:"segments"`
-	NumberOfFrameworks int                      `json:"number_of_frameworks"`
-	NumberOfMolecules  int                      `json:"number_of_molecules"`
-	Frameworks         []map[string]interface{} `json:"frameworks"`
-	Cached             bool                     `json:"cached"`
+	Filename           string               `json:"filename"`
+	Formula            string               `json:"formula"`
+	Segments           int                  `json:"segments"`
+	NumberOfFrameworks int                  `json:"number_of_frameworks"`
+	NumberOfMolecules  int                  `json:"number_of_molecules"`
+	Frameworks         []StructureComponent `json:"frameworks"`
+	Molecules          []StructureComponent `json:"molecules"`
+	Cached             bool                 `json:"cached"`
+}
+
+type BlockingSphere struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Z      float64 `json:"z"`
+	Radius float64 `json:"radius"`
 }
 
 type BlockingSpheresResponse struct {
-	Channels      []interface{} `json:"channels"`
-	Pockets       []interface{} `json:"pockets"`
-	NodesAssigned []interface{} `json:"nodes_assigned"`
-	Raw           string        `json:"raw"`
-	Cached        bool          `json:"cached"`
+	NumberOfSpheres int              `json:"number_of_spheres"`
+	Spheres         []BlockingSphere `json:"spheres"`
+	Channels        int              `json:"channels"`
+	Pockets         int              `json:"pockets"`
+	Raw             string           `json:"raw"`
+	Cached          bool             `json:"cached"`
 }
 
 type OpenMetalSitesResponse struct {
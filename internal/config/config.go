@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"runtime"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v2"
@@ -14,6 +15,8 @@ type Config struct {
 	Concurrency ConcurrencyConfig `yaml:"concurrency"`
 	Cache       CacheConfig       `yaml:"cache"`
 	Logging     LoggingConfig     `yaml:"logging"`
+	// Probes maps guest molecule names to probe radii in Angstrom
+	Probes map[string]float64 `yaml:"probes"`
 }
 
 type ServerConfig struct {
@@ -36,6 +39,7 @@ type ConcurrencyConfig struct {
 	RateLimitPerIP       int   `yaml:"rate_limit_per_ip"`
 	MaxFileSize          int64 `yaml:"max_file_size"`
 	MaxConcurrentUploads int   `yaml:"max_concurrent_uploads"`
+	MaxBatchJobs         int   `yaml:"max_batch_jobs"`
 }
 
 type CacheConfig struct {
@@ -51,29 +55,58 @@ type LoggingConfig struct {
 	Output string `yaml:"output"`
 }
 
+// LoadConfig reads a YAML config file over the built-in defaults. Use Load
+// to also apply environment variables and flags.
 func LoadConfig(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
+	cfg, err := LoadDefaultConfig()
 	if err != nil {
 		return nil, err
 	}
-
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	if err := loadFile(cfg, path); err != nil {
 		return nil, err
 	}
+	applyDefaults(cfg)
+	return cfg, nil
+}
 
-	// Set defaults
-	if cfg.Concurrency.MaxWorkers <= 0 {
-		cfg.Concurrency.MaxWorkers = runtime.NumCPU()
+// loadFile layers a YAML file over cfg; fields the file omits keep their
+// current values. Configured probes extend or override the current ones.
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
 	}
-	if cfg.Cache.Shards <= 0 {
-		cfg.Cache.Shards = 32
+
+	probes := cfg.Probes
+	cfg.Probes = nil
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		cfg.Probes = probes
+		return err
+	}
+	for name, radius := range cfg.Probes {
+		setProbe(probes, name, radius)
 	}
-	if cfg.Cache.TTL < 0 {
-		cfg.Cache.TTL = 3600 * time.Second
+	cfg.Probes = probes
+
+	return nil
+}
+
+// applyDefaults resolves max_workers: 0, which means one worker per CPU.
+// Other out-of-range values are left for Validate to report.
+func applyDefaults(cfg *Config) {
+	if cfg.Concurrency.MaxWorkers == 0 {
+		cfg.Concurrency.MaxWorkers = runtime.NumCPU()
 	}
+}
 
-	return &cfg, nil
+// setProbe adds a named probe, replacing any entry that differs only in case
+func setProbe(probes map[string]float64, name string, radius float64) {
+	for existing := range probes {
+		if strings.EqualFold(existing, name) {
+			delete(probes, existing)
+		}
+	}
+	probes[name] = radius
 }
 
 func LoadDefaultConfig() (*Config, error) {
@@ -82,8 +115,8 @@ func LoadDefaultConfig() (*Config, error) {
 			Port:               "8080",
 			Host:               "0.0.0.0",
 			ReadTimeout:        30 * time.Second,
-			WriteTimeout:       30 * time.Second,
-			MaxMultipartMemory: 32 << 20, // 32MB
+			WriteTimeout:       6 * time.Minute, // must cover Zeo.Timeout
+			MaxMultipartMemory: 32 << 20,        // 32MB
 		},
 		Zeo: ZeoConfig{
 			ExecutablePath: "network",
@@ -96,6 +129,7 @@ func LoadDefaultConfig() (*Config, error) {
 			RateLimitPerIP:       10,
 			MaxFileSize:          int64(100) << 20, // 100MB
 			MaxConcurrentUploads: 50,
+			MaxBatchJobs:         4,
 		},
 		Cache: CacheConfig{
 			Enabled:   true,
@@ -108,5 +142,43 @@ func LoadDefaultConfig() (*Config, error) {
 			Format: "json",
 			Output: "stdout",
 		},
+		Probes: defaultProbes(),
 	}, nil
 }
+
+// HandlerTimeout is how long a synchronous request may run its analyses:
+// the write timeout less a margin for stopping Zeo++, which may take the
+// runner's WaitDelay, and writing the response. It is 0 when writes have no
+// timeout.
+func (s *ServerConfig) HandlerTimeout() time.Duration {
+	return s.WriteTimeout - min(5*time.Second, s.WriteTimeout/2)
+}
+
+// ProbeRadius looks up a named probe, ignoring case, and returns its
+// canonical name and radius
+func (c *Config) ProbeRadius(name string) (string, float64, bool) {
+	name = strings.TrimSpace(name)
+	for probe, radius := range c.Probes {
+		if strings.EqualFold(probe, name) {
+			return probe, radius, true
+		}
+	}
+	return "", 0, false
+}
+
+// defaultProbes returns half the kinetic diameters of common guest molecules
+func defaultProbes() map[string]float64 {
+	return map[string]float64{
+		"H2":  1.445,
+		"He":  1.30,
+		"N2":  1.82,
+		"O2":  1.73,
+		"CO":  1.88,
+		"CO2": 1.65,
+		"CH4": 1.90,
+		"Ar":  1.70,
+		"Kr":  1.80,
+		"Xe":  1.98,
+		"H2O": 1.325,
+	}
+}
@@ -0,0 +1,234 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultConfigPath is read when no config file is selected. Unlike an
+	// explicitly selected file, it may be missing.
+	DefaultConfigPath = "config/config.yaml"
+
+	configPathEnv = "ZEO_API_CONFIG"
+)
+
+// setting ties one config field to its environment variables and flag. The
+// first listed environment variable is the canonical one; the rest are
+// accepted aliases.
+type setting struct {
+	flag    string
+	usage   string
+	envs    []string
+	boolean bool
+	set     func(cfg *Config, value string) error
+}
+
+func stringSetting(flag, usage string, field func(cfg *Config) *string, envs ...string) setting {
+	return setting{flag: flag, usage: usage, envs: envs, set: func(cfg *Config, v string) error {
+		*field(cfg) = v
+		return nil
+	}}
+}
+
+func intSetting(flag, usage string, field func(cfg *Config) *int, envs ...string) setting {
+	return setting{flag: flag, usage: usage, envs: envs, set: func(cfg *Config, v string) error {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", v)
+		}
+		*field(cfg) = n
+		return nil
+	}}
+}
+
+func int64Setting(flag, usage string, field func(cfg *Config) *int64, envs ...string) setting {
+	return setting{flag: flag, usage: usage, envs: envs, set: func(cfg *Config, v string) error {
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", v)
+		}
+		*field(cfg) = n
+		return nil
+	}}
+}
+
+func boolSetting(flag, usage string, field func(cfg *Config) *bool, envs ...string) setting {
+	return setting{flag: flag, usage: usage, envs: envs, boolean: true, set: func(cfg *Config, v string) error {
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", v)
+		}
+		*field(cfg) = b
+		return nil
+	}}
+}
+
+// durationSetting accepts Go durations ("90s", "5m") and plain seconds
+// ("3600")
+func durationSetting(flag, usage string, field func(cfg *Config) *time.Duration, envs ...string) setting {
+	return setting{flag: flag, usage: usage, envs: envs, set: func(cfg *Config, v string) error {
+		v = strings.TrimSpace(v)
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			*field(cfg) = time.Duration(seconds * float64(time.Second))
+			return nil
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", v)
+		}
+		*field(cfg) = d
+		return nil
+	}}
+}
+
+func settings() []setting {
+	return []setting{
+		stringSetting("port", "HTTP listen port",
+			func(c *Config) *string { return &c.Server.Port }, "SERVER_PORT"),
+		stringSetting("host", "HTTP listen address",
+			func(c *Config) *string { return &c.Server.Host }, "SERVER_HOST"),
+		durationSetting("read-timeout", "HTTP read timeout",
+			func(c *Config) *time.Duration { return &c.Server.ReadTimeout }, "SERVER_READ_TIMEOUT"),
+		durationSetting("write-timeout", "HTTP write timeout",
+			func(c *Config) *time.Duration { return &c.Server.WriteTimeout }, "SERVER_WRITE_TIMEOUT"),
+		int64Setting("max-multipart-memory", "bytes of a multipart form held in memory",
+			func(c *Config) *int64 { return &c.Server.MaxMultipartMemory }, "SERVER_MAX_MULTIPART_MEMORY"),
+		stringSetting("zeo-exec-path", "path to the Zeo++ network executable",
+			func(c *Config) *string { return &c.Zeo.ExecutablePath }, "ZEO_EXEC_PATH"),
+		stringSetting("zeo-workspace", "working directory for uploads and runs",
+			func(c *Config) *string { return &c.Zeo.Workdir }, "ZEO_WORKSPACE"),
+		durationSetting("zeo-timeout", "timeout of one Zeo++ run",
+			func(c *Config) *time.Duration { return &c.Zeo.Timeout }, "ZEO_TIMEOUT"),
+		intSetting("max-workers", "worker pool size (0 = number of CPUs)",
+			func(c *Config) *int { return &c.Concurrency.MaxWorkers }, "MAX_WORKERS"),
+		intSetting("max-queue-size", "job queue capacity",
+			func(c *Config) *int { return &c.Concurrency.MaxQueueSize }, "MAX_QUEUE_SIZE"),
+		intSetting("rate-limit-per-ip", "requests per second per client IP",
+			func(c *Config) *int { return &c.Concurrency.RateLimitPerIP }, "RATE_LIMIT_PER_IP"),
+		int64Setting("max-file-size", "maximum upload size in bytes",
+			func(c *Config) *int64 { return &c.Concurrency.MaxFileSize }, "MAX_FILE_SIZE"),
+		intSetting("max-concurrent-uploads", "requests processed at once",
+			func(c *Config) *int { return &c.Concurrency.MaxConcurrentUploads }, "MAX_CONCURRENT_UPLOADS"),
+		intSetting("max-batch-jobs", "batch and replicate jobs running at once",
+			func(c *Config) *int { return &c.Concurrency.MaxBatchJobs }, "MAX_BATCH_JOBS"),
+		boolSetting("cache-enabled", "cache analysis results",
+			func(c *Config) *bool { return &c.Cache.Enabled }, "CACHE_ENABLED", "ENABLE_CACHE"),
+		durationSetting("cache-ttl", "cache entry lifetime",
+			func(c *Config) *time.Duration { return &c.Cache.TTL }, "CACHE_TTL"),
+		int64Setting("cache-max-size-mb", "cache size limit in MB",
+			func(c *Config) *int64 { return &c.Cache.MaxSizeMB }, "CACHE_MAX_SIZE_MB"),
+		intSetting("cache-shards", "number of cache shards",
+			func(c *Config) *int { return &c.Cache.Shards }, "CACHE_SHARDS"),
+		stringSetting("log-level", "log level: debug, info, warn or error",
+			func(c *Config) *string { return &c.Logging.Level }, "LOG_LEVEL"),
+		stringSetting("log-format", "log format: json or text",
+			func(c *Config) *string { return &c.Logging.Format }, "LOG_FORMAT"),
+		stringSetting("log-output", "log destination: stdout, stderr or a file path",
+			func(c *Config) *string { return &c.Logging.Output }, "LOG_OUTPUT"),
+		{
+			flag:  "probes",
+			usage: "named probes as NAME=radius pairs, comma separated",
+			envs:  []string{"ZEO_PROBES"},
+			set:   func(cfg *Config, v string) error { return parseProbes(v, cfg.Probes) },
+		},
+	}
+}
+
+// Load builds the configuration from, in increasing precedence, built-in
+// defaults, a YAML file, environment variables and command-line flags. The
+// file is selected with -config or ZEO_API_CONFIG and defaults to
+// DefaultConfigPath. Load registers its flags on flags, so callers can add
+// flags of their own before calling it, and parses args with it.
+func Load(flags *flag.FlagSet, args []string) (*Config, error) {
+	configPath := flags.String("config", "", "path to the YAML config file (env "+configPathEnv+", default "+DefaultConfigPath+")")
+
+	all := settings()
+	flagValues := make(map[string]string)
+	for _, s := range all {
+		name := s.flag
+		usage := fmt.Sprintf("%s (env %s)", s.usage, s.envs[0])
+		record := func(value string) error {
+			flagValues[name] = value
+			return nil
+		}
+		if s.boolean {
+			flags.BoolFunc(name, usage, record)
+		} else {
+			flags.Func(name, usage, record)
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	path, explicit := *configPath, true
+	if path == "" {
+		path = os.Getenv(configPathEnv)
+	}
+	if path == "" {
+		path, explicit = DefaultConfigPath, false
+	}
+
+	cfg, err := LoadDefaultConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := loadFile(cfg, path); err != nil {
+		if explicit || !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+
+	for _, s := range all {
+		for _, env := range s.envs {
+			value, ok := os.LookupEnv(env)
+			if !ok {
+				continue
+			}
+			if err := s.set(cfg, value); err != nil {
+				return nil, fmt.Errorf("environment variable %s: %w", env, err)
+			}
+			break
+		}
+	}
+
+	for _, s := range all {
+		value, ok := flagValues[s.flag]
+		if !ok {
+			continue
+		}
+		if err := s.set(cfg, value); err != nil {
+			return nil, fmt.Errorf("flag -%s: %w", s.flag, err)
+		}
+	}
+
+	applyDefaults(cfg)
+	return cfg, nil
+}
+
+// parseProbes adds "N2=1.82,CO2=1.65" style entries to probes, replacing
+// entries that differ only in case
+func parseProbes(value string, probes map[string]float64) error {
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, raw, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return fmt.Errorf("invalid probe %q, expected NAME=radius", pair)
+		}
+		radius, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return fmt.Errorf("invalid radius for probe %s: %q", name, raw)
+		}
+		setProbe(probes, name, radius)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Probe radii must fall in the range analyses accept for probe_radius
+const (
+	minProbeRadius = 0.1
+	maxProbeRadius = 10.0
+)
+
+// Validate checks value ranges and consistency between fields, reporting
+// every problem found rather than only the first
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	port, err := strconv.Atoi(c.Server.Port)
+	check(err == nil && port >= 1 && port <= 65535,
+		"server.port must be a port number between 1 and 65535, got %q", c.Server.Port)
+	check(c.Server.ReadTimeout > 0, "server.read_timeout must be positive, got %s", c.Server.ReadTimeout)
+	check(c.Server.WriteTimeout >= 0, "server.write_timeout must be 0 (no timeout) or positive, got %s", c.Server.WriteTimeout)
+	check(c.Server.MaxMultipartMemory > 0, "server.max_multipart_memory must be positive, got %d", c.Server.MaxMultipartMemory)
+
+	check(strings.TrimSpace(c.Zeo.ExecutablePath) != "", "zeo.executable_path must not be empty")
+	check(strings.TrimSpace(c.Zeo.Workdir) != "", "zeo.workdir must not be empty")
+	check(c.Zeo.Timeout > 0, "zeo.timeout must be positive, got %s", c.Zeo.Timeout)
+	// Synchronous endpoints hold the response open for a whole Zeo++ run and
+	// stop it HandlerTimeout after the request started; with no write
+	// timeout they run until Zeo++ finishes
+	check(c.Server.WriteTimeout <= 0 || c.Zeo.Timeout <= c.Server.HandlerTimeout(),
+		"zeo.timeout (%s) must leave server.write_timeout (%s) at least %s to respond, or synchronous requests time out before Zeo++ finishes",
+		c.Zeo.Timeout, c.Server.WriteTimeout, c.Server.WriteTimeout-c.Server.HandlerTimeout())
+
+	check(c.Concurrency.MaxWorkers >= 0, "concurrency.max_workers must be 0 (one per CPU) or positive, got %d", c.Concurrency.MaxWorkers)
+	check(c.Concurrency.MaxQueueSize > 0, "concurrency.max_queue_size must be positive, got %d", c.Concurrency.MaxQueueSize)
+	check(c.Concurrency.RateLimitPerIP > 0, "concurrency.rate_limit_per_ip must be positive, got %d", c.Concurrency.RateLimitPerIP)
+	check(c.Concurrency.MaxFileSize > 0, "concurrency.max_file_size must be positive, got %d", c.Concurrency.MaxFileSize)
+	check(c.Concurrency.MaxConcurrentUploads > 0,
+		"concurrency.max_concurrent_uploads must be positive, got %d; with 0 every request is rejected", c.Concurrency.MaxConcurrentUploads)
+	check(c.Concurrency.MaxBatchJobs > 0,
+		"concurrency.max_batch_jobs must be positive, got %d; with 0 every batch is rejected", c.Concurrency.MaxBatchJobs)
+
+	check(c.Cache.Shards > 0, "cache.shards must be positive, got %d", c.Cache.Shards)
+	check(c.Cache.TTL >= 0, "cache.ttl must be 0 (no expiry) or positive, got %s", c.Cache.TTL)
+	check(c.Cache.MaxSizeMB >= 0, "cache.max_size_mb must not be negative, got %d", c.Cache.MaxSizeMB)
+
+	switch strings.ToLower(c.Logging.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("logging.level must be debug, info, warn or error, got %q", c.Logging.Level))
+	}
+	switch strings.ToLower(c.Logging.Format) {
+	case "json", "text":
+	default:
+		errs = append(errs, fmt.Errorf("logging.format must be json or text, got %q", c.Logging.Format))
+	}
+	check(strings.TrimSpace(c.Logging.Output) != "", "logging.output must be stdout, stderr or a file path")
+
+	names := make([]string, 0, len(c.Probes))
+	for name := range c.Probes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		radius := c.Probes[name]
+		check(strings.TrimSpace(name) != "" && !strings.ContainsAny(name, ",="), "probes: invalid probe name %q", name)
+		check(radius >= minProbeRadius && radius <= maxProbeRadius,
+			"probes.%s must be between %g and %g, got %g", name, minProbeRadius, maxProbeRadius, radius)
+	}
+
+	return errors.Join(errs...)
+}
@@ -3,6 +3,8 @@ package cache
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -43,8 +45,8 @@ func NewCache(cfg *config.CacheConfig, baseDir string) *Cache {
 
 func (c *Cache) Get(key string) (map[string][]byte, bool) {
 	shard := c.getShard(key)
-	shard.mu.RLock()
-	defer shard.mu.RUnlock()
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
 
 	item, exists := shard.items[key]
 	if !exists {
@@ -85,11 +87,30 @@ func (c *Cache) getShard(key string) *shard {
 	return &c.shards[index]
 }
 
-func GenerateCacheKey(filePath string, args []string) string {
+// GenerateCacheKey derives a cache key from the SHA-256 of the structure
+// content, the normalized Zeo++ arguments, the content of any auxiliary
+// input files and the Zeo++ binary version, so identical submissions hit
+// regardless of upload name or location.
+func GenerateCacheKey(structureHash string, args []string, inputFiles map[string][]byte, zeoVersion string) string {
 	h := sha256.New()
-	h.Write([]byte(filePath))
+	h.Write([]byte(structureHash))
+	h.Write([]byte{0})
+	h.Write([]byte(zeoVersion))
 	for _, arg := range args {
-		h.Write([]byte(arg))
+		h.Write([]byte{0})
+		h.Write([]byte(strings.TrimSpace(arg)))
+	}
+
+	names := make([]string, 0, len(inputFiles))
+	for name := range inputFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		content := sha256.Sum256(inputFiles[name])
+		h.Write([]byte{0})
+		h.Write([]byte(name))
+		h.Write(content[:])
 	}
 	return hex.EncodeToString(h.Sum(nil))
 }
@@ -0,0 +1,289 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"zeo-api/internal/core/pool"
+)
+
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+var (
+	ErrNotFound        = errors.New("job not found")
+	ErrAlreadyFinished = errors.New("job already finished")
+	// ErrTooManyJobs is returned by Coordinate when the maximum number of
+	// coordinated jobs are already running
+	ErrTooManyJobs = errors.New("too many coordinated jobs running")
+	// ErrShutdown is returned for jobs submitted after Shutdown
+	ErrShutdown = errors.New("job manager is shut down")
+)
+
+// Func performs the work of a job; it must return promptly once ctx is done
+type Func func(ctx context.Context) (interface{}, error)
+
+// CoordinatorFunc performs a job made of many units of work, reporting how
+// many of them are done as it goes
+type CoordinatorFunc func(ctx context.Context, report func(done, total int)) (interface{}, error)
+
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// Job is a point-in-time view of a submitted job
+type Job struct {
+	ID           string      `json:"id"`
+	AnalysisType string      `json:"analysis_type"`
+	Status       Status      `json:"status"`
+	Result       interface{} `json:"result,omitempty"`
+	Error        string      `json:"error,omitempty"`
+	Progress     *Progress   `json:"progress,omitempty"`
+	CreatedAt    time.Time   `json:"created_at"`
+	StartedAt    *time.Time  `json:"started_at,omitempty"`
+	FinishedAt   *time.Time  `json:"finished_at,omitempty"`
+}
+
+func (j *Job) finished() bool {
+	return j.Status == StatusCompleted || j.Status == StatusFailed || j.Status == StatusCancelled
+}
+
+type entry struct {
+	job    Job
+	cancel context.CancelFunc
+}
+
+type Manager struct {
+	pool         *pool.WorkerPool
+	jobs         map[string]*entry
+	mu           sync.RWMutex
+	coordinators chan struct{}
+	shutdown     bool
+}
+
+// NewManager creates a manager running at most maxCoordinated jobs started
+// with Coordinate at once
+func NewManager(workerPool *pool.WorkerPool, maxCoordinated int) *Manager {
+	return &Manager{
+		pool:         workerPool,
+		jobs:         make(map[string]*entry),
+		coordinators: make(chan struct{}, maxCoordinated),
+	}
+}
+
+// Submit queues fn on the worker pool and returns the queued job. It fails
+// with pool.ErrQueueFull instead of blocking when the queue is at capacity.
+func (m *Manager) Submit(analysisType string, fn Func) (Job, error) {
+	ctx, e, err := m.register(analysisType)
+	if err != nil {
+		return Job{}, err
+	}
+
+	// Once queued, the job belongs to its worker; take the view returned to
+	// the caller first
+	job := m.snapshot(e)
+	task := pool.Task{
+		ID:   job.ID,
+		Func: func() error { return m.run(ctx, e, fn) },
+		// Still queued at shutdown: fn only gets to clean up
+		Cancel: func() {
+			e.cancel()
+			_ = m.run(ctx, e, fn)
+		},
+	}
+	if err := m.pool.TrySubmit(task); err != nil {
+		e.cancel()
+		m.mu.Lock()
+		delete(m.jobs, job.ID)
+		m.mu.Unlock()
+		return Job{}, err
+	}
+
+	return job, nil
+}
+
+// Coordinate starts fn in its own goroutine rather than on a pool worker, so
+// that it can itself fan units of work out across the pool without holding
+// a worker while it waits for them. It fails with ErrTooManyJobs when the
+// maximum number of coordinated jobs are already running.
+func (m *Manager) Coordinate(analysisType string, fn CoordinatorFunc) (Job, error) {
+	select {
+	case m.coordinators <- struct{}{}:
+	default:
+		return Job{}, ErrTooManyJobs
+	}
+
+	ctx, e, err := m.register(analysisType)
+	if err != nil {
+		<-m.coordinators
+		return Job{}, err
+	}
+
+	report := func(done, total int) {
+		m.mu.Lock()
+		e.job.Progress = &Progress{Done: done, Total: total}
+		m.mu.Unlock()
+	}
+	job := m.snapshot(e)
+	go func() {
+		defer func() { <-m.coordinators }()
+		_ = m.run(ctx, e, func(ctx context.Context) (interface{}, error) {
+			return fn(ctx, report)
+		})
+	}()
+
+	return job, nil
+}
+
+func (m *Manager) register(analysisType string) (context.Context, *entry, error) {
+	id, err := newJobID()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &entry{
+		job: Job{
+			ID:           id,
+			AnalysisType: analysisType,
+			Status:       StatusQueued,
+			CreatedAt:    time.Now().UTC(),
+		},
+		cancel: cancel,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.shutdown {
+		cancel()
+		return nil, nil, ErrShutdown
+	}
+	m.jobs[id] = e
+
+	return ctx, e, nil
+}
+
+// snapshot copies the job of e under the lock
+func (m *Manager) snapshot(e *entry) Job {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return e.job
+}
+
+func (m *Manager) run(ctx context.Context, e *entry, fn Func) error {
+	defer e.cancel()
+
+	m.mu.Lock()
+	if e.job.Status == StatusQueued {
+		now := time.Now().UTC()
+		e.job.Status = StatusRunning
+		e.job.StartedAt = &now
+	}
+	m.mu.Unlock()
+
+	// fn is invoked even for jobs cancelled while queued so that it can
+	// release whatever it holds; it is expected to bail out on ctx
+	result, err := fn(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().UTC()
+	e.job.FinishedAt = &now
+	switch {
+	case ctx.Err() == context.Canceled:
+		e.job.Status = StatusCancelled
+	case err != nil:
+		e.job.Status = StatusFailed
+		e.job.Error = err.Error()
+	default:
+		e.job.Status = StatusCompleted
+		e.job.Result = result
+	}
+	return err
+}
+
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	e, exists := m.jobs[id]
+	if !exists {
+		return Job{}, false
+	}
+	return e.job, true
+}
+
+// Cancel stops a queued or running job. The job reports StatusCancelled once
+// its worker has observed the cancellation.
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, exists := m.jobs[id]
+	if !exists {
+		return Job{}, ErrNotFound
+	}
+	if e.job.finished() {
+		return e.job, ErrAlreadyFinished
+	}
+
+	e.cancel()
+	if e.job.Status == StatusQueued {
+		now := time.Now().UTC()
+		e.job.Status = StatusCancelled
+		e.job.FinishedAt = &now
+	}
+	return e.job, nil
+}
+
+// Shutdown cancels every unfinished job and refuses new ones, so that
+// running Zeo++ processes are killed instead of being waited for when the
+// worker pool stops
+func (m *Manager) Shutdown() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.shutdown = true
+	for _, e := range m.jobs {
+		if !e.job.finished() {
+			e.cancel()
+		}
+	}
+}
+
+// Cleanup periodically forgets finished jobs older than retention
+func (m *Manager) Cleanup(retention time.Duration) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.mu.Lock()
+		for id, e := range m.jobs {
+			if e.job.finished() && e.job.FinishedAt != nil && time.Since(*e.job.FinishedAt) > retention {
+				delete(m.jobs, id)
+			}
+		}
+		m.mu.Unlock()
+	}
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
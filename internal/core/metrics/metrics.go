@@ -0,0 +1,67 @@
+// Package metrics defines the service's Prometheus metrics
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Default is the registry served on /metrics
+var Default = prometheus.NewRegistry()
+
+var factory = promauto.With(Default)
+
+var (
+	httpBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+	zeoBuckets  = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+)
+
+var (
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "zeo_api_http_requests_total",
+		Help: "HTTP requests served, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "zeo_api_http_request_duration_seconds",
+		Help:    "Time taken to serve HTTP requests, by method and route.",
+		Buckets: httpBuckets,
+	}, []string{"method", "route"})
+
+	ZeoRunDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "zeo_api_zeo_run_duration_seconds",
+		Help:    "Wall time of Zeo++ executions, by analysis type.",
+		Buckets: zeoBuckets,
+	}, []string{"analysis_type"})
+	ZeoRuns = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "zeo_api_zeo_runs_total",
+		Help: "Zeo++ executions, by analysis type and exit code.",
+	}, []string{"analysis_type", "exit_code"})
+	ZeoTimeouts = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "zeo_api_zeo_timeouts_total",
+		Help: "Zeo++ executions killed for exceeding the configured timeout, by analysis type.",
+	}, []string{"analysis_type"})
+
+	CacheLookups = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "zeo_api_cache_lookups_total",
+		Help: "Analyses by cache outcome (hit, miss or bypassed) and analysis type.",
+	}, []string{"analysis_type", "result"})
+)
+
+// GaugeFunc registers a gauge whose value is read from fn on every scrape
+func GaugeFunc(name, help string, fn func() float64) {
+	factory.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help}, fn)
+}
+
+// CounterFunc registers a counter whose value is read from fn on every
+// scrape; fn must never decrease
+func CounterFunc(name, help string, fn func() float64) {
+	factory.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, fn)
+}
+
+// Handler serves the Default registry for Prometheus to scrape
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Default, promhttp.HandlerOpts{})
+}
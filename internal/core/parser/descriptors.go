@@ -0,0 +1,164 @@
+package parser
+
+// Units of the descriptor set
+const (
+	UnitAngstrom       = "Å"
+	UnitAngstrom2      = "Å^2"
+	UnitAngstrom3      = "Å^3"
+	UnitDensity        = "g/cm^3"
+	UnitGravimetricSA  = "m^2/g"
+	UnitVolumetricSA   = "m^2/cm^3"
+	UnitGravimetricVol = "cm^3/g"
+	UnitDimensionless  = "1"
+)
+
+// m^2/cm^3 per Å^2/Å^3
+const angstromAreaPerVolumeToVolumetricSA = 1e4
+
+// Quantity is a descriptor value with its unit
+type Quantity struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+// DescriptorInputs are the parsed outputs descriptors are derived from. Any
+// of them may be nil; descriptors that depend only on missing outputs are
+// left out.
+type DescriptorInputs struct {
+	PoreDiameter     *PoreDiameterResult
+	SurfaceArea      *SurfaceAreaResult
+	AccessibleVolume *AccessibleVolumeResult
+	ProbeVolume      *ProbeVolumeResult
+	Channels         *ChannelAnalysisResult
+}
+
+// Descriptors is a standardized set of textural descriptors
+type Descriptors struct {
+	Density                     *Quantity `json:"density,omitempty"`
+	UnitcellVolume              *Quantity `json:"unitcell_volume,omitempty"`
+	LargestCavityDiameter       *Quantity `json:"largest_cavity_diameter,omitempty"`
+	PoreLimitingDiameter        *Quantity `json:"pore_limiting_diameter,omitempty"`
+	LargestFreePathDiameter     *Quantity `json:"largest_free_path_diameter,omitempty"`
+	LCDToPLDRatio               *Quantity `json:"lcd_to_pld_ratio,omitempty"`
+	ASA                         *Quantity `json:"asa,omitempty"`
+	VolumetricASA               *Quantity `json:"volumetric_asa,omitempty"`
+	GravimetricASA              *Quantity `json:"gravimetric_asa,omitempty"`
+	VoidFraction                *Quantity `json:"void_fraction,omitempty"`
+	PoreVolume                  *Quantity `json:"pore_volume,omitempty"`
+	AccessibleVolumeFraction    *Quantity `json:"accessible_volume_fraction,omitempty"`
+	GravimetricAccessibleVolume *Quantity `json:"gravimetric_accessible_volume,omitempty"`
+	ChannelDimensionality       *Quantity `json:"channel_dimensionality,omitempty"`
+	NumberOfChannels            *Quantity `json:"number_of_channels,omitempty"`
+}
+
+func quantity(value float64, unit string) *Quantity {
+	return &Quantity{Value: value, Unit: unit}
+}
+
+// ComputeDescriptors combines -res, -sa, -vol, -volpo and -chan results.
+// Derived values are computed from per-unit-cell quantities where Zeo++
+// reports them and fall back to Zeo++'s own converted values otherwise. The
+// void fraction is the probe-occupiable one (POAV), and the pore volume is
+// that fraction divided by the density.
+func ComputeDescriptors(in DescriptorInputs) *Descriptors {
+	d := &Descriptors{}
+
+	density, cellVolume := structureProperties(in)
+	if density > 0 {
+		d.Density = quantity(density, UnitDensity)
+	}
+	if cellVolume > 0 {
+		d.UnitcellVolume = quantity(cellVolume, UnitAngstrom3)
+	}
+
+	// Pore diameters, from -res or else from the -chan summary
+	var di, df, dif float64
+	switch {
+	case in.PoreDiameter != nil:
+		di, df, dif = in.PoreDiameter.IncludedDiameter, in.PoreDiameter.FreeDiameter, in.PoreDiameter.IncludedAlongFree
+	case in.Channels != nil:
+		di, df, dif = in.Channels.IncludedDiameter, in.Channels.FreeDiameter, in.Channels.IncludedAlongFree
+	}
+	if in.PoreDiameter != nil || in.Channels != nil {
+		d.LargestCavityDiameter = quantity(di, UnitAngstrom)
+		d.PoreLimitingDiameter = quantity(df, UnitAngstrom)
+		d.LargestFreePathDiameter = quantity(dif, UnitAngstrom)
+		if df > 0 {
+			d.LCDToPLDRatio = quantity(di/df, UnitDimensionless)
+		}
+	}
+
+	if sa := in.SurfaceArea; sa != nil {
+		d.ASA = quantity(sa.ASAUnitcell, UnitAngstrom2)
+		volumetric := sa.ASAVolume
+		if cellVolume > 0 {
+			volumetric = sa.ASAUnitcell / cellVolume * angstromAreaPerVolumeToVolumetricSA
+		}
+		d.VolumetricASA = quantity(volumetric, UnitVolumetricSA)
+		gravimetric := sa.ASAMass
+		if density > 0 {
+			gravimetric = volumetric / density
+		}
+		d.GravimetricASA = quantity(gravimetric, UnitGravimetricSA)
+	}
+
+	if volpo := in.ProbeVolume; volpo != nil {
+		fraction := volpo.POAVFraction
+		if cellVolume > 0 {
+			fraction = volpo.POAVUnitcell / cellVolume
+		}
+		d.VoidFraction = quantity(fraction, UnitDimensionless)
+		poreVolume := volpo.POAVMass
+		if density > 0 {
+			poreVolume = fraction / density
+		}
+		d.PoreVolume = quantity(poreVolume, UnitGravimetricVol)
+	}
+
+	if vol := in.AccessibleVolume; vol != nil {
+		fraction := vol.AV["fraction"]
+		d.AccessibleVolumeFraction = quantity(fraction, UnitDimensionless)
+		gravimetric := vol.AV["cm3_g"]
+		if density > 0 {
+			gravimetric = fraction / density
+		}
+		d.GravimetricAccessibleVolume = quantity(gravimetric, UnitGravimetricVol)
+		d.NumberOfChannels = quantity(float64(vol.NumberOfChannels), UnitDimensionless)
+	}
+
+	if in.Channels != nil {
+		d.ChannelDimensionality = quantity(float64(in.Channels.Dimension), UnitDimensionless)
+	}
+
+	return d
+}
+
+// structureProperties returns the density (g/cm^3) and unit cell volume
+// (Å^3) from the first output that reports them. Without a labeled header
+// the density can still be recovered from the two converted surface areas.
+func structureProperties(in DescriptorInputs) (float64, float64) {
+	var density, cellVolume float64
+	if in.AccessibleVolume != nil {
+		density, cellVolume = in.AccessibleVolume.Density, in.AccessibleVolume.UnitcellVolume
+	}
+	if sa := in.SurfaceArea; sa != nil {
+		if density <= 0 {
+			density = sa.Density
+		}
+		if cellVolume <= 0 {
+			cellVolume = sa.UnitcellVolume
+		}
+	}
+	if volpo := in.ProbeVolume; volpo != nil {
+		if density <= 0 {
+			density = volpo.Density
+		}
+		if cellVolume <= 0 {
+			cellVolume = volpo.UnitcellVolume
+		}
+	}
+	if sa := in.SurfaceArea; density <= 0 && sa != nil && sa.ASAMass > 0 {
+		density = sa.ASAVolume / sa.ASAMass
+	}
+	return density, cellVolume
+}
@@ -2,6 +2,8 @@ package parser
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -12,29 +14,43 @@ type PoreDiameterResult struct {
 	IncludedAlongFree float64 `json:"included_along_free"`
 }
 
+// SurfaceAreaResult holds -sa output. UnitcellVolume and Density are only
+// known when Zeo++ writes its labeled header.
 type SurfaceAreaResult struct {
-	ASAUnitcell  float64 `json:"asa_unitcell"`
-	ASAVolume    float64 `json:"asa_volume"`
-	ASAMass      float64 `json:"asa_mass"`
-	NASAUnitcell float64 `json:"nasa_unitcell"`
-	NASAVolume   float64 `json:"nasa_volume"`
-	NASAMass     float64 `json:"nasa_mass"`
+	UnitcellVolume float64 `json:"unitcell_volume,omitempty"`
+	Density        float64 `json:"density,omitempty"`
+	ASAUnitcell    float64 `json:"asa_unitcell"`
+	ASAVolume      float64 `json:"asa_volume"`
+	ASAMass        float64 `json:"asa_mass"`
+	NASAUnitcell   float64 `json:"nasa_unitcell"`
+	NASAVolume     float64 `json:"nasa_volume"`
+	NASAMass       float64 `json:"nasa_mass"`
 }
 
+// AccessibleVolumeResult holds -vol output. AV and NAV are keyed by unit:
+// "a3" (Å³ per unit cell), "fraction" (volume fraction) and "cm3_g" (cm³/g).
 type AccessibleVolumeResult struct {
-	UnitcellVolume float64            `json:"unitcell_volume"`
-	Density        float64            `json:"density"`
-	AV             map[string]float64 `json:"av"`
-	NAV            map[string]float64 `json:"nav"`
+	UnitcellVolume   float64            `json:"unitcell_volume"`
+	Density          float64            `json:"density"`
+	AV               map[string]float64 `json:"av"`
+	NAV              map[string]float64 `json:"nav"`
+	NumberOfChannels int                `json:"number_of_channels"`
+	ChannelVolumes   []float64          `json:"channel_volumes"`
+	NumberOfPockets  int                `json:"number_of_pockets"`
+	PocketVolumes    []float64          `json:"pocket_volumes"`
 }
 
+// ProbeVolumeResult holds -volpo output. UnitcellVolume and Density are only
+// known when Zeo++ writes its labeled header.
 type ProbeVolumeResult struct {
-	POAVUnitcell  float64 `json:"poav_unitcell"`
-	POAVFraction  float64 `json:"poav_fraction"`
-	POAVMass      float64 `json:"poav_mass"`
-	PONAVUnitcell float64 `json:"ponav_unitcell"`
-	PONAVFraction float64 `json:"ponav_fraction"`
-	PONAVMass     float64 `json:"ponav_mass"`
+	UnitcellVolume float64 `json:"unitcell_volume,omitempty"`
+	Density        float64 `json:"density,omitempty"`
+	POAVUnitcell   float64 `json:"poav_unitcell"`
+	POAVFraction   float64 `json:"poav_fraction"`
+	POAVMass       float64 `json:"poav_mass"`
+	PONAVUnitcell  float64 `json:"ponav_unitcell"`
+	PONAVFraction  float64 `json:"ponav_fraction"`
+	PONAVMass      float64 `json:"ponav_mass"`
 }
 
 type ChannelAnalysisResult struct {
@@ -44,20 +60,90 @@ type ChannelAnalysisResult struct {
 	IncludedAlongFree float64 `json:"included_along_free"`
 }
 
+// StructureComponent is one connected framework or molecule in -strinfo output
+type StructureComponent struct {
+	Index          int    `json:"index"`
+	Dimensionality int    `json:"dimensionality"`
+	Atoms          int    `json:"atoms"`
+	Formula        string `json:"formula"`
+}
+
 type FrameworkInfoResult struct {
-	Filename           string                   `json:"filename"`
-	Formula            string                   `json:"formula"`
-	Segments           int                      `json:"segments"`
-	NumberOfFrameworks int                      `json:"number_of_frameworks"`
-	NumberOfMolecules  int                      `json:"number_of_molecules"`
-	Frameworks         []map[string]interface{} `json:"frameworks"`
+	Filename           string               `json:"filename"`
+	Formula            string               `json:"formula"`
+	Segments           int                  `json:"segments"`
+	NumberOfFrameworks int                  `json:"number_of_frameworks"`
+	NumberOfMolecules  int                  `json:"number_of_molecules"`
+	Frameworks         []StructureComponent `json:"frameworks"`
+	Molecules          []StructureComponent `json:"molecules"`
+}
+
+type BlockingSphere struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Z      float64 `json:"z"`
+	Radius float64 `json:"radius"`
 }
 
 type BlockingSpheresResult struct {
-	Channels      []interface{} `json:"channels"`
-	Pockets       []interface{} `json:"pockets"`
-	NodesAssigned []interface{} `json:"nodes_assigned"`
-	Raw           string        `json:"raw"`
+	NumberOfSpheres int              `json:"number_of_spheres"`
+	Spheres         []BlockingSphere `json:"spheres"`
+	Channels        int              `json:"channels"`
+	Pockets         int              `json:"pockets"`
+	Raw             string           `json:"raw"`
+}
+
+type PoreSizeDistributionResult struct {
+	BinSize                    float64   `json:"bin_size"`
+	NumberOfBins               int       `json:"number_of_bins"`
+	From                       float64   `json:"from"`
+	To                         float64   `json:"to"`
+	TotalSamples               int       `json:"total_samples"`
+	AccessibleSamples          int       `json:"accessible_samples"`
+	FractionInNodeSpheres      float64   `json:"fraction_in_node_spheres"`
+	FractionOutsideNodeSpheres float64   `json:"fraction_outside_node_spheres"`
+	Bins                       []float64 `json:"bins"`
+	Counts                     []int     `json:"counts"`
+	Cumulative                 []float64 `json:"cumulative"`
+	Derivative                 []float64 `json:"derivative"`
+}
+
+// RayAtomResult is a histogram of ray lengths from -ray_atom output. Bins
+// holds the lower edge of each bin in Å.
+type RayAtomResult struct {
+	NumberOfRays int       `json:"number_of_rays"`
+	MinLength    float64   `json:"min_length"`
+	MaxLength    float64   `json:"max_length"`
+	MeanLength   float64   `json:"mean_length"`
+	BinWidth     float64   `json:"bin_width"`
+	Bins         []float64 `json:"bins"`
+	Counts       []int     `json:"counts"`
+}
+
+type VoronoiNode struct {
+	ID     int     `json:"id"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Z      float64 `json:"z"`
+	Radius float64 `json:"radius"`
+	Atoms  []int   `json:"atoms"`
+}
+
+// VoronoiEdge connects two nodes. Shift is the unit cell offset of the
+// destination node and Radius the largest sphere that can pass the edge.
+type VoronoiEdge struct {
+	From   int     `json:"from"`
+	To     int     `json:"to"`
+	Radius float64 `json:"radius"`
+	Shift  [3]int  `json:"shift"`
+	Length float64 `json:"length"`
+}
+
+type VoronoiNetworkResult struct {
+	NumberOfNodes int           `json:"number_of_nodes"`
+	NumberOfEdges int           `json:"number_of_edges"`
+	Nodes         []VoronoiNode `json:"nodes"`
+	Edges         []VoronoiEdge `json:"edges"`
 }
 
 type OpenMetalSitesResult struct {
@@ -71,10 +157,12 @@ func ParsePoreDiameter(data string) (*PoreDiameterResult, error) {
 		return nil, fmt.Errorf("empty output")
 	}
 
+	// Zeo++ prefixes the values with the output filename
 	parts := strings.Fields(lines[len(lines)-1])
 	if len(parts) < 3 {
 		return nil, fmt.Errorf("invalid format: expected 3 values, got %d", len(parts))
 	}
+	parts = parts[len(parts)-3:]
 
 	included, err1 := strconv.ParseFloat(parts[0], 64)
 	free, err2 := strconv.ParseFloat(parts[1], 64)
@@ -98,6 +186,21 @@ func ParseSurfaceArea(data string) (*SurfaceAreaResult, error) {
 		return nil, fmt.Errorf("empty output")
 	}
 
+	values := parseLabeledValues(data)
+	if _, ok := values["ASA_A^2"]; ok {
+		first := firstLabeledValue(values)
+		return &SurfaceAreaResult{
+			UnitcellVolume: first("Unitcell_volume"),
+			Density:        first("Density"),
+			ASAUnitcell:    first("ASA_A^2"),
+			ASAVolume:      first("ASA_m^2/cm^3"),
+			ASAMass:        first("ASA_m^2/g"),
+			NASAUnitcell:   first("NASA_A^2"),
+			NASAVolume:     first("NASA_m^2/cm^3"),
+			NASAMass:       first("NASA_m^2/g"),
+		}, nil
+	}
+
 	lastLine := lines[len(lines)-1]
 	parts := strings.Fields(lastLine)
 	if len(parts) < 6 {
@@ -121,15 +224,46 @@ func ParseSurfaceArea(data string) (*SurfaceAreaResult, error) {
 	}, nil
 }
 
-// ParseAccessibleVolume parses Zeo++ -vol output
+// ParseAccessibleVolume parses Zeo++ -vol output, e.g.
+//
+//	@ EDI.vol Unitcell_volume: 307.484 Density: 1.62239 AV_A^3: 22.6493 AV_Volume_fraction: 0.07366 AV_cm^3/g: 0.0454022 NAV_A^3: 0 NAV_Volume_fraction: 0 NAV_cm^3/g: 0
+//	Number_of_channels: 1 Channel_volume_A^3: 22.6493
+//	Number_of_pockets: 0 Pocket_volume_A^3:
 func ParseAccessibleVolume(data string) (*AccessibleVolumeResult, error) {
 	lines := strings.Split(strings.TrimSpace(data), "\n")
-	if len(lines) == 0 {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
 		return nil, fmt.Errorf("empty output")
 	}
 
-	lastLine := lines[len(lines)-1]
-	parts := strings.Fields(lastLine)
+	values := parseLabeledValues(data)
+	if _, ok := values["Unitcell_volume"]; !ok {
+		return parseAccessibleVolumeColumns(lines[len(lines)-1])
+	}
+
+	first := firstLabeledValue(values)
+	return &AccessibleVolumeResult{
+		UnitcellVolume: first("Unitcell_volume"),
+		Density:        first("Density"),
+		AV: map[string]float64{
+			"a3":       first("AV_A^3"),
+			"fraction": first("AV_Volume_fraction"),
+			"cm3_g":    first("AV_cm^3/g"),
+		},
+		NAV: map[string]float64{
+			"a3":       first("NAV_A^3"),
+			"fraction": first("NAV_Volume_fraction"),
+			"cm3_g":    first("NAV_cm^3/g"),
+		},
+		NumberOfChannels: int(first("Number_of_channels")),
+		ChannelVolumes:   append([]float64{}, values["Channel_volume_A^3"]...),
+		NumberOfPockets:  int(first("Number_of_pockets")),
+		PocketVolumes:    append([]float64{}, values["Pocket_volume_A^3"]...),
+	}, nil
+}
+
+// parseAccessibleVolumeColumns handles the bare "unitcell density av" form
+func parseAccessibleVolumeColumns(line string) (*AccessibleVolumeResult, error) {
+	parts := strings.Fields(line)
 	if len(parts) < 3 {
 		return nil, fmt.Errorf("invalid format: expected 3 values, got %d", len(parts))
 	}
@@ -141,11 +275,49 @@ func ParseAccessibleVolume(data string) (*AccessibleVolumeResult, error) {
 	return &AccessibleVolumeResult{
 		UnitcellVolume: unitcellVolume,
 		Density:        density,
-		AV:             map[string]float64{"value": av},
-		NAV:            map[string]float64{"value": 0}, // Default if not provided
+		AV:             map[string]float64{"a3": av},
+		NAV:            map[string]float64{},
+		ChannelVolumes: []float64{},
+		PocketVolumes:  []float64{},
 	}, nil
 }
 
+// parseLabeledValues collects the numbers following each "Label:" token in
+// Zeo++ summary output. Labels with no numbers map to an empty slice.
+func parseLabeledValues(data string) map[string][]float64 {
+	values := make(map[string][]float64)
+	label := ""
+	for _, field := range strings.Fields(data) {
+		if strings.HasSuffix(field, ":") && len(field) > 1 {
+			label = strings.TrimSuffix(field, ":")
+			if _, exists := values[label]; !exists {
+				values[label] = []float64{}
+			}
+			continue
+		}
+		if label == "" {
+			continue
+		}
+		if v, err := strconv.ParseFloat(field, 64); err == nil {
+			values[label] = append(values[label], v)
+		} else {
+			label = ""
+		}
+	}
+	return values
+}
+
+// firstLabeledValue returns a lookup of the first value of each label,
+// defaulting to zero
+func firstLabeledValue(values map[string][]float64) func(key string) float64 {
+	return func(key string) float64 {
+		if v := values[key]; len(v) > 0 {
+			return v[0]
+		}
+		return 0
+	}
+}
+
 // ParseProbeVolume parses Zeo++ -volpo output
 func ParseProbeVolume(data string) (*ProbeVolumeResult, error) {
 	lines := strings.Split(strings.TrimSpace(data), "\n")
@@ -153,6 +325,21 @@ func ParseProbeVolume(data string) (*ProbeVolumeResult, error) {
 		return nil, fmt.Errorf("empty output")
 	}
 
+	values := parseLabeledValues(data)
+	if _, ok := values["POAV_A^3"]; ok {
+		first := firstLabeledValue(values)
+		return &ProbeVolumeResult{
+			UnitcellVolume: first("Unitcell_volume"),
+			Density:        first("Density"),
+			POAVUnitcell:   first("POAV_A^3"),
+			POAVFraction:   first("POAV_Volume_fraction"),
+			POAVMass:       first("POAV_cm^3/g"),
+			PONAVUnitcell:  first("PONAV_A^3"),
+			PONAVFraction:  first("PONAV_Volume_fraction"),
+			PONAVMass:      first("PONAV_cm^3/g"),
+		}, nil
+	}
+
 	lastLine := lines[len(lines)-1]
 	parts := strings.Fields(lastLine)
 	if len(parts) < 6 {
@@ -176,6 +363,8 @@ func ParseProbeVolume(data string) (*ProbeVolumeResult, error) {
 	}, nil
 }
 
+var channelDimensionalityPattern = regexp.MustCompile(`channels identified of dimensionality((?:\s+-?\d+)*)`)
+
 // ParseChannelAnalysis parses Zeo++ -chan output
 func ParseChannelAnalysis(data string) (*ChannelAnalysisResult, error) {
 	lines := strings.Split(strings.TrimSpace(data), "\n")
@@ -183,6 +372,27 @@ func ParseChannelAnalysis(data string) (*ChannelAnalysisResult, error) {
 		return nil, fmt.Errorf("empty output")
 	}
 
+	// Zeo++ lists the dimensionality of every channel, then one line per
+	// channel and a summary line holding the largest diameters
+	if match := channelDimensionalityPattern.FindStringSubmatch(lines[0]); match != nil {
+		result := &ChannelAnalysisResult{}
+		for _, field := range strings.Fields(match[1]) {
+			if dimension, err := strconv.Atoi(field); err == nil && dimension > result.Dimension {
+				result.Dimension = dimension
+			}
+		}
+		for _, line := range lines[1:] {
+			parts := strings.Fields(line)
+			if len(parts) < 5 || !strings.Contains(parts[1], "summary") {
+				continue
+			}
+			result.IncludedDiameter, _ = strconv.ParseFloat(parts[2], 64)
+			result.FreeDiameter, _ = strconv.ParseFloat(parts[3], 64)
+			result.IncludedAlongFree, _ = strconv.ParseFloat(parts[4], 64)
+		}
+		return result, nil
+	}
+
 	lastLine := lines[len(lines)-1]
 	parts := strings.Fields(lastLine)
 	if len(parts) < 4 {
@@ -202,41 +412,491 @@ func ParseChannelAnalysis(data string) (*ChannelAnalysisResult, error) {
 	}, nil
 }
 
-// ParseFrameworkInfo parses Zeo++ -strinfo output
+var (
+	strinfoHeaderPattern    = regexp.MustCompile(`^(\S+)\s+(\d+)\s+segments?:\s+(\d+)\s+frameworks?(?:\(s\))?.*?\band\s+(\d+)\s+molecules?`)
+	strinfoComponentPattern = regexp.MustCompile(`(?i)^(framework|molecule)\s*#?\s*(\d+)`)
+	dimensionalityPattern   = regexp.MustCompile(`(?i)\((\d)D\)|dimensionality\s*:?\s*(\d)`)
+	atomCountPattern        = regexp.MustCompile(`(?i)(\d+)\s+atoms?\b|atoms?\s*:\s*(\d+)`)
+	componentFormulaPattern = regexp.MustCompile(`(?i)formula\s*:?\s*([A-Za-z0-9]+)`)
+	formulaElementPattern   = regexp.MustCompile(`([A-Z][a-z]?)(\d*)`)
+)
+
+// ParseFrameworkInfo parses Zeo++ -strinfo output: a summary line
+// "<file> N segments: F framework(s) (...) and M molecule(s) (...)" followed
+// by one line per framework or molecule with its dimensionality, atom count
+// and formula.
 func ParseFrameworkInfo(data string) (*FrameworkInfoResult, error) {
 	lines := strings.Split(strings.TrimSpace(data), "\n")
-	if len(lines) == 0 {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
 		return nil, fmt.Errorf("empty output")
 	}
 
-	// Simple parsing - in real implementation, this would be more sophisticated
 	result := &FrameworkInfoResult{
-		Filename:           "",
-		Formula:            "",
-		Segments:           1,
-		NumberOfFrameworks: 1,
-		NumberOfMolecules:  0,
-		Frameworks:         []map[string]interface{}{},
+		Frameworks: []StructureComponent{},
+		Molecules:  []StructureComponent{},
 	}
 
+	headerFound := false
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
-		if strings.Contains(line, "Framework") {
-			result.NumberOfFrameworks++
+
+		if m := strinfoHeaderPattern.FindStringSubmatch(line); m != nil {
+			headerFound = true
+			result.Filename = m[1]
+			result.Segments, _ = strconv.Atoi(m[2])
+			result.NumberOfFrameworks, _ = strconv.Atoi(m[3])
+			result.NumberOfMolecules, _ = strconv.Atoi(m[4])
+			continue
+		}
+
+		m := strinfoComponentPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		// Dimensionality stays -1 when Zeo++ does not report it
+		component := StructureComponent{Dimensionality: -1}
+		component.Index, _ = strconv.Atoi(m[2])
+		if dm := dimensionalityPattern.FindStringSubmatch(line); dm != nil {
+			component.Dimensionality, _ = strconv.Atoi(dm[1] + dm[2])
+		}
+		if am := atomCountPattern.FindStringSubmatch(line); am != nil {
+			component.Atoms, _ = strconv.Atoi(am[1] + am[2])
+		}
+		if fm := componentFormulaPattern.FindStringSubmatch(line); fm != nil {
+			component.Formula = fm[1]
 		}
+
+		if strings.EqualFold(m[1], "framework") {
+			result.Frameworks = append(result.Frameworks, component)
+		} else {
+			result.Molecules = append(result.Molecules, component)
+		}
+	}
+
+	if !headerFound && len(result.Frameworks) == 0 && len(result.Molecules) == 0 {
+		return nil, fmt.Errorf("invalid format: no structure information found")
+	}
+
+	// Fall back to the listed components when the summary line is missing
+	if !headerFound {
+		result.NumberOfFrameworks = len(result.Frameworks)
+		result.NumberOfMolecules = len(result.Molecules)
+		result.Segments = result.NumberOfFrameworks + result.NumberOfMolecules
+	}
+
+	var formulas []string
+	for _, component := range append(append([]StructureComponent{}, result.Frameworks...), result.Molecules...) {
+		formulas = append(formulas, component.Formula)
 	}
+	result.Formula = combineFormulas(formulas)
 
 	return result, nil
 }
 
-// ParseBlockingSpheres parses Zeo++ -block output
-func ParseBlockingSpheres(data string) (*BlockingSpheresResult, error) {
-	return &BlockingSpheresResult{
-		Channels:      []interface{}{},
-		Pockets:       []interface{}{},
-		NodesAssigned: []interface{}{},
-		Raw:           data,
-	}, nil
+// combineFormulas sums element counts over several formulas and writes the
+// total in Hill order (C, H, then alphabetical)
+func combineFormulas(formulas []string) string {
+	counts := make(map[string]int)
+	for _, formula := range formulas {
+		for _, m := range formulaElementPattern.FindAllStringSubmatch(formula, -1) {
+			n := 1
+			if m[2] != "" {
+				n, _ = strconv.Atoi(m[2])
+			}
+			counts[m[1]] += n
+		}
+	}
+	if len(counts) == 0 {
+		return ""
+	}
+
+	elements := make([]string, 0, len(counts))
+	for element := range counts {
+		elements = append(elements, element)
+	}
+	sort.Slice(elements, func(i, j int) bool {
+		ri, rj := hillRank(elements[i], counts), hillRank(elements[j], counts)
+		if ri != rj {
+			return ri < rj
+		}
+		return elements[i] < elements[j]
+	})
+
+	var b strings.Builder
+	for _, element := range elements {
+		b.WriteString(element)
+		if counts[element] != 1 {
+			b.WriteString(strconv.Itoa(counts[element]))
+		}
+	}
+	return b.String()
+}
+
+func hillRank(element string, counts map[string]int) int {
+	if _, hasCarbon := counts["C"]; !hasCarbon {
+		return 2
+	}
+	switch element {
+	case "C":
+		return 0
+	case "H":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// The counts must stand alone so that e.g. "0.5 channels" is not read as 5
+var (
+	channelCountPattern = regexp.MustCompile(`(?i)(?:^|\s)(\d+)\s+channels?\b`)
+	pocketCountPattern  = regexp.MustCompile(`(?i)(?:^|\s)(\d+)\s+pockets?\b`)
+)
+
+// ParseBlockingSpheres parses Zeo++ -block output. The .block file holds the
+// sphere count followed by one "x y z radius" line per sphere; the number of
+// accessible channels and inaccessible pockets is only reported on stdout.
+func ParseBlockingSpheres(data string, stdout string) (*BlockingSpheresResult, error) {
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
+		return nil, fmt.Errorf("empty output")
+	}
+
+	declared, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse blocking sphere count: %w", err)
+	}
+
+	result := &BlockingSpheresResult{
+		Spheres: []BlockingSphere{},
+		Raw:     data,
+	}
+
+	for _, line := range lines[1:] {
+		parts := strings.Fields(line)
+		if len(parts) < 4 {
+			continue
+		}
+
+		// Coordinates and radius are the last four columns
+		values := make([]float64, 4)
+		valid := true
+		for i, part := range parts[len(parts)-4:] {
+			values[i], err = strconv.ParseFloat(part, 64)
+			if err != nil {
+				valid = false
+				break
+			}
+		}
+		if !valid {
+			continue
+		}
+
+		result.Spheres = append(result.Spheres, BlockingSphere{
+			X:      values[0],
+			Y:      values[1],
+			Z:      values[2],
+			Radius: values[3],
+		})
+	}
+
+	if len(result.Spheres) != declared {
+		return nil, fmt.Errorf("invalid format: expected %d blocking spheres, got %d", declared, len(result.Spheres))
+	}
+	result.NumberOfSpheres = declared
+
+	if m := channelCountPattern.FindStringSubmatch(stdout); m != nil {
+		result.Channels, _ = strconv.Atoi(m[1])
+	}
+	if m := pocketCountPattern.FindStringSubmatch(stdout); m != nil {
+		result.Pockets, _ = strconv.Atoi(m[1])
+	}
+
+	return result, nil
+}
+
+// ParsePoreSizeDistribution parses Zeo++ -psd histogram output
+func ParsePoreSizeDistribution(data string) (*PoreSizeDistributionResult, error) {
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
+		return nil, fmt.Errorf("empty output")
+	}
+
+	result := &PoreSizeDistributionResult{
+		Bins:       []float64{},
+		Counts:     []int{},
+		Cumulative: []float64{},
+		Derivative: []float64{},
+	}
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		// Header lines are "Key: value"
+		if key, value, found := strings.Cut(line, ":"); found {
+			value = strings.TrimSpace(value)
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "bin size (a)":
+				result.BinSize, _ = strconv.ParseFloat(value, 64)
+			case "number of bins":
+				result.NumberOfBins, _ = strconv.Atoi(value)
+			case "from":
+				result.From, _ = strconv.ParseFloat(value, 64)
+			case "to":
+				result.To, _ = strconv.ParseFloat(value, 64)
+			case "total samples":
+				result.TotalSamples, _ = strconv.Atoi(value)
+			case "accessible samples":
+				result.AccessibleSamples, _ = strconv.Atoi(value)
+			case "fraction of sample points in node spheres":
+				result.FractionInNodeSpheres, _ = strconv.ParseFloat(value, 64)
+			case "fraction of sample points outside node spheres":
+				result.FractionOutsideNodeSpheres, _ = strconv.ParseFloat(value, 64)
+			}
+			continue
+		}
+
+		// Histogram rows are "bin count cumulative derivative"; anything else
+		// (title, column header) is skipped
+		parts := strings.Fields(line)
+		if len(parts) < 4 {
+			continue
+		}
+		bin, err1 := strconv.ParseFloat(parts[0], 64)
+		count, err2 := strconv.ParseFloat(parts[1], 64)
+		cumulative, err3 := strconv.ParseFloat(parts[2], 64)
+		derivative, err4 := strconv.ParseFloat(parts[3], 64)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			continue
+		}
+		result.Bins = append(result.Bins, bin)
+		result.Counts = append(result.Counts, int(count))
+		result.Cumulative = append(result.Cumulative, cumulative)
+		result.Derivative = append(result.Derivative, derivative)
+	}
+
+	if len(result.Bins) == 0 {
+		return nil, fmt.Errorf("invalid format: no histogram rows found")
+	}
+	if result.NumberOfBins == 0 {
+		result.NumberOfBins = len(result.Bins)
+	}
+
+	return result, nil
+}
+
+// CoarsenPoreSizeDistribution merges runs of adjacent Zeo++ bins so that the
+// histogram has at most maxBins bins. Zeo++ bins at a fixed width, so a
+// histogram can only be made coarser; one already within maxBins is returned
+// unchanged. Each merged bin starts at its first bin and takes that bin's
+// cumulative value, the sum of the counts and the mean derivative.
+func CoarsenPoreSizeDistribution(r *PoreSizeDistributionResult, maxBins int) *PoreSizeDistributionResult {
+	n := len(r.Bins)
+	if maxBins <= 0 || n <= maxBins {
+		return r
+	}
+	group := (n + maxBins - 1) / maxBins
+
+	out := *r
+	out.Bins, out.Counts, out.Cumulative, out.Derivative = nil, nil, nil, nil
+	for start := 0; start < n; start += group {
+		end := min(start+group, n)
+		count := 0
+		derivative := 0.0
+		for i := start; i < end; i++ {
+			count += r.Counts[i]
+			derivative += r.Derivative[i]
+		}
+		out.Bins = append(out.Bins, r.Bins[start])
+		out.Counts = append(out.Counts, count)
+		out.Cumulative = append(out.Cumulative, r.Cumulative[start])
+		out.Derivative = append(out.Derivative, derivative/float64(end-start))
+	}
+	out.BinSize = r.BinSize * float64(group)
+	out.NumberOfBins = len(out.Bins)
+	return &out
+}
+
+// rayHistogramBins is the number of equal-width bins between zero and the
+// longest ray in a -ray_atom histogram
+const rayHistogramBins = 50
+
+// ParseRayAtom parses Zeo++ -ray_atom output, one ray per line with the ray
+// length in the last column, into a ray length histogram. Ray rows are the
+// lines made only of numbers that have the most common number of columns,
+// so header and count lines are not taken for rays.
+func ParseRayAtom(data string) (*RayAtomResult, error) {
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
+		return nil, fmt.Errorf("empty output")
+	}
+
+	var rows [][]float64
+	widths := make(map[int]int)
+	for _, line := range lines {
+		row, ok := parseNumericRow(line)
+		if !ok {
+			continue
+		}
+		rows = append(rows, row)
+		widths[len(row)]++
+	}
+	rayWidth := 0
+	for width, count := range widths {
+		if count > widths[rayWidth] || (count == widths[rayWidth] && width > rayWidth) {
+			rayWidth = width
+		}
+	}
+
+	var lengths []float64
+	for _, row := range rows {
+		if len(row) != rayWidth || row[len(row)-1] < 0 {
+			continue
+		}
+		lengths = append(lengths, row[len(row)-1])
+	}
+	if len(lengths) == 0 {
+		return nil, fmt.Errorf("invalid format: no ray lengths found")
+	}
+
+	result := &RayAtomResult{
+		NumberOfRays: len(lengths),
+		MinLength:    lengths[0],
+		MaxLength:    lengths[0],
+		Bins:         make([]float64, rayHistogramBins),
+		Counts:       make([]int, rayHistogramBins),
+	}
+
+	sum := 0.0
+	for _, length := range lengths {
+		sum += length
+		if length < result.MinLength {
+			result.MinLength = length
+		}
+		if length > result.MaxLength {
+			result.MaxLength = length
+		}
+	}
+	result.MeanLength = sum / float64(len(lengths))
+
+	result.BinWidth = result.MaxLength / rayHistogramBins
+	for i := range result.Bins {
+		result.Bins[i] = float64(i) * result.BinWidth
+	}
+	for _, length := range lengths {
+		bin := rayHistogramBins - 1
+		if result.BinWidth > 0 {
+			bin = int(length / result.BinWidth)
+		}
+		if bin >= rayHistogramBins {
+			bin = rayHistogramBins - 1
+		}
+		result.Counts[bin]++
+	}
+
+	return result, nil
+}
+
+// parseNumericRow parses a line made only of numbers
+func parseNumericRow(line string) ([]float64, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, false
+	}
+	row := make([]float64, len(fields))
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, false
+		}
+		row[i] = v
+	}
+	return row, true
+}
+
+// ParseVoronoiNetwork parses Zeo++ -nt2 output: a "Vertex table:" section
+// of "id x y z radius atom..." lines followed by an "Edge table:" section of
+// "from -> to radius da db dc length" lines
+func ParseVoronoiNetwork(data string) (*VoronoiNetworkResult, error) {
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
+		return nil, fmt.Errorf("empty output")
+	}
+
+	result := &VoronoiNetworkResult{
+		Nodes: []VoronoiNode{},
+		Edges: []VoronoiEdge{},
+	}
+
+	section := ""
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(strings.ToLower(line), "vertex table"):
+			section = "vertex"
+			continue
+		case strings.HasPrefix(strings.ToLower(line), "edge table"):
+			section = "edge"
+			continue
+		}
+
+		parts := strings.Fields(line)
+		switch section {
+		case "vertex":
+			if len(parts) < 5 {
+				return nil, fmt.Errorf("invalid vertex on line %d: expected at least 5 values, got %d", i+1, len(parts))
+			}
+			node := VoronoiNode{Atoms: []int{}}
+			var errs [5]error
+			node.ID, errs[0] = strconv.Atoi(parts[0])
+			node.X, errs[1] = strconv.ParseFloat(parts[1], 64)
+			node.Y, errs[2] = strconv.ParseFloat(parts[2], 64)
+			node.Z, errs[3] = strconv.ParseFloat(parts[3], 64)
+			node.Radius, errs[4] = strconv.ParseFloat(parts[4], 64)
+			for _, err := range errs {
+				if err != nil {
+					return nil, fmt.Errorf("invalid vertex on line %d: %w", i+1, err)
+				}
+			}
+			for _, part := range parts[5:] {
+				if atom, err := strconv.Atoi(part); err == nil {
+					node.Atoms = append(node.Atoms, atom)
+				}
+			}
+			result.Nodes = append(result.Nodes, node)
+		case "edge":
+			if len(parts) < 8 || parts[1] != "->" {
+				return nil, fmt.Errorf("invalid edge on line %d: expected \"from -> to radius da db dc length\"", i+1)
+			}
+			edge := VoronoiEdge{}
+			var errs [7]error
+			edge.From, errs[0] = strconv.Atoi(parts[0])
+			edge.To, errs[1] = strconv.Atoi(parts[2])
+			edge.Radius, errs[2] = strconv.ParseFloat(parts[3], 64)
+			edge.Shift[0], errs[3] = strconv.Atoi(parts[4])
+			edge.Shift[1], errs[4] = strconv.Atoi(parts[5])
+			edge.Shift[2], errs[5] = strconv.Atoi(parts[6])
+			edge.Length, errs[6] = strconv.ParseFloat(parts[7], 64)
+			for _, err := range errs {
+				if err != nil {
+					return nil, fmt.Errorf("invalid edge on line %d: %w", i+1, err)
+				}
+			}
+			result.Edges = append(result.Edges, edge)
+		}
+	}
+
+	if len(result.Nodes) == 0 {
+		return nil, fmt.Errorf("invalid format: no Voronoi nodes found")
+	}
+	result.NumberOfNodes = len(result.Nodes)
+	result.NumberOfEdges = len(result.Edges)
+
+	return result, nil
 }
 
 // ParseOpenMetalSites parses Zeo++ -oms output
@@ -257,8 +917,10 @@ func ParseOpenMetalSites(data string) (*OpenMetalSitesResult, error) {
 	}, nil
 }
 
-// ParseOutputFile parses the specified output file based on analysis type
-func ParseOutputFile(analysisType string, data string) (interface{}, error) {
+// ParseOutputFile parses the specified output file based on analysis type.
+// stdout is the Zeo++ console output of the same run, which some analyses
+// report part of their results on.
+func ParseOutputFile(analysisType string, data string, stdout string) (interface{}, error) {
 	switch analysisType {
 	case "pore_diameter":
 		return ParsePoreDiameter(data)
@@ -273,9 +935,15 @@ func ParseOutputFile(analysisType string, data string) (interface{}, error) {
 	case "framework_info":
 		return ParseFrameworkInfo(data)
 	case "blocking_spheres":
-		return ParseBlockingSpheres(data)
+		return ParseBlockingSpheres(data, stdout)
 	case "open_metal_sites":
 		return ParseOpenMetalSites(data)
+	case "pore_size_dist":
+		return ParsePoreSizeDistribution(data)
+	case "ray_atom":
+		return ParseRayAtom(data)
+	case "voronoi_network":
+		return ParseVoronoiNetwork(data)
 	default:
 		return nil, fmt.Errorf("unsupported analysis type: %s", analysisType)
 	}
@@ -0,0 +1,293 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// The fixtures in testdata follow the output formats in the Zeo++
+// documentation, using its EDI examples where it gives them
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestParsePoreDiameter(t *testing.T) {
+	got, err := ParsePoreDiameter(readFixture(t, "EDI.res"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &PoreDiameterResult{IncludedDiameter: 4.89082, FreeDiameter: 3.03868, IncludedAlongFree: 4.89082}
+	if *got != *want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseSurfaceArea(t *testing.T) {
+	got, err := ParseSurfaceArea(readFixture(t, "EDI.sa"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &SurfaceAreaResult{
+		UnitcellVolume: 307.484,
+		Density:        1.62239,
+		ASAUnitcell:    60.7713,
+		ASAVolume:      1976.4,
+		ASAMass:        1218.21,
+	}
+	if *got != *want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseAccessibleVolume(t *testing.T) {
+	got, err := ParseAccessibleVolume(readFixture(t, "EDI.vol"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &AccessibleVolumeResult{
+		UnitcellVolume:   307.484,
+		Density:          1.62239,
+		AV:               map[string]float64{"a3": 22.6493, "fraction": 0.07366, "cm3_g": 0.0454022},
+		NAV:              map[string]float64{"a3": 0, "fraction": 0, "cm3_g": 0},
+		NumberOfChannels: 1,
+		ChannelVolumes:   []float64{22.6493},
+		NumberOfPockets:  0,
+		PocketVolumes:    []float64{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseProbeVolume(t *testing.T) {
+	got, err := ParseProbeVolume(readFixture(t, "EDI.volpo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ProbeVolumeResult{
+		UnitcellVolume: 307.484,
+		Density:        1.62239,
+		POAVUnitcell:   131.284,
+		POAVFraction:   0.42696,
+		POAVMass:       0.263168,
+	}
+	if *got != *want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseChannelAnalysis(t *testing.T) {
+	got, err := ParseChannelAnalysis(readFixture(t, "EDI.chan"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ChannelAnalysisResult{Dimension: 3, IncludedDiameter: 4.89082, FreeDiameter: 3.03868, IncludedAlongFree: 4.89082}
+	if *got != *want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseFrameworkInfo(t *testing.T) {
+	got, err := ParseFrameworkInfo(readFixture(t, "MOF5.strinfo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &FrameworkInfoResult{
+		Filename:           "MOF5.cssr",
+		Formula:            "C192H98O105Zn32",
+		Segments:           2,
+		NumberOfFrameworks: 1,
+		NumberOfMolecules:  1,
+		Frameworks:         []StructureComponent{{Index: 0, Dimensionality: 3, Atoms: 424, Formula: "C192H96O104Zn32"}},
+		Molecules:          []StructureComponent{{Index: 0, Dimensionality: 0, Atoms: 3, Formula: "H2O"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseFrameworkInfoWithoutSummary(t *testing.T) {
+	got, err := ParseFrameworkInfo("Framework 0: 12 atoms, formula: SiO2\nFramework 1 dimensionality 2: 6 atoms\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Segments != 2 || got.NumberOfFrameworks != 2 || got.NumberOfMolecules != 0 {
+		t.Errorf("got %d segments, %d frameworks, %d molecules, want 2, 2, 0",
+			got.Segments, got.NumberOfFrameworks, got.NumberOfMolecules)
+	}
+	if d := got.Frameworks[0].Dimensionality; d != -1 {
+		t.Errorf("unreported dimensionality = %d, want -1", d)
+	}
+	if d := got.Frameworks[1].Dimensionality; d != 2 {
+		t.Errorf("dimensionality = %d, want 2", d)
+	}
+
+	if _, err := ParseFrameworkInfo("no structure here\n"); err == nil {
+		t.Error("expected an error for output without structure information")
+	}
+}
+
+func TestParseBlockingSpheres(t *testing.T) {
+	data := readFixture(t, "EDI.block")
+	got, err := ParseBlockingSpheres(data, readFixture(t, "EDI.block.stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &BlockingSpheresResult{
+		NumberOfSpheres: 2,
+		Spheres: []BlockingSphere{
+			{X: 1.55044, Y: 2.81052, Z: 4.87452, Radius: 1.34432},
+			{X: 5.42341, Y: 5.41235, Z: 1.91290, Radius: 1.12012},
+		},
+		Channels: 1,
+		Pockets:  2,
+		Raw:      data,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := ParseBlockingSpheres("3\n1 2 3 1.5\n", ""); err == nil {
+		t.Error("expected an error when fewer spheres than declared are listed")
+	}
+}
+
+func TestParseBlockingSpheresStdoutCounts(t *testing.T) {
+	tests := []struct {
+		stdout   string
+		channels int
+		pockets  int
+	}{
+		{"Identified 1 channels and 2 pockets.", 1, 2},
+		{"3 channels identified of dimensionality 3 3 1\n0 pockets identified\n", 3, 0},
+		{"1 CHANNEL and 1 POCKET", 1, 1},
+		{"0.5 channels, 1.5 pockets", 0, 0},
+		{"", 0, 0},
+	}
+	for _, tt := range tests {
+		got, err := ParseBlockingSpheres("1\n0 0 0 1\n", tt.stdout)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.stdout, err)
+		}
+		if got.Channels != tt.channels || got.Pockets != tt.pockets {
+			t.Errorf("%q: got %d channels, %d pockets, want %d, %d",
+				tt.stdout, got.Channels, got.Pockets, tt.channels, tt.pockets)
+		}
+	}
+}
+
+func TestParsePoreSizeDistribution(t *testing.T) {
+	got, err := ParsePoreSizeDistribution(readFixture(t, "EDI.psd_histo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &PoreSizeDistributionResult{
+		BinSize:                    0.1,
+		NumberOfBins:               6,
+		From:                       0,
+		To:                         0.6,
+		TotalSamples:               1000,
+		AccessibleSamples:          400,
+		FractionInNodeSpheres:      0.6,
+		FractionOutsideNodeSpheres: 0.4,
+		Bins:                       []float64{0, 0.1, 0.2, 0.3, 0.4, 0.5},
+		Counts:                     []int{0, 40, 80, 120, 100, 60},
+		Cumulative:                 []float64{1, 1, 0.9, 0.7, 0.4, 0.15},
+		Derivative:                 []float64{0, -0.1, -0.2, -0.3, -0.25, -0.15},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestCoarsenPoreSizeDistribution(t *testing.T) {
+	r, err := ParsePoreSizeDistribution(readFixture(t, "EDI.psd_histo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := CoarsenPoreSizeDistribution(r, 6); got != r {
+		t.Error("a histogram within maxBins should be returned unchanged")
+	}
+
+	got := CoarsenPoreSizeDistribution(r, 4)
+	if got.NumberOfBins != 3 || got.BinSize != 0.2 {
+		t.Errorf("got %d bins of %g, want 3 bins of 0.2", got.NumberOfBins, got.BinSize)
+	}
+	if want := []float64{0, 0.2, 0.4}; !reflect.DeepEqual(got.Bins, want) {
+		t.Errorf("bins = %v, want %v", got.Bins, want)
+	}
+	if want := []int{40, 200, 160}; !reflect.DeepEqual(got.Counts, want) {
+		t.Errorf("counts = %v, want %v", got.Counts, want)
+	}
+	if want := []float64{1, 0.9, 0.4}; !reflect.DeepEqual(got.Cumulative, want) {
+		t.Errorf("cumulative = %v, want %v", got.Cumulative, want)
+	}
+	if len(r.Bins) != 6 {
+		t.Error("coarsening modified the original histogram")
+	}
+}
+
+func TestParseRayAtom(t *testing.T) {
+	got, err := ParseRayAtom(readFixture(t, "EDI.ray_atom"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.NumberOfRays != 6 {
+		t.Errorf("number of rays = %d, want 6; header lines must not count as rays", got.NumberOfRays)
+	}
+	if got.MinLength != 1 || got.MaxLength != 5 || got.MeanLength != 3 {
+		t.Errorf("min/max/mean = %g/%g/%g, want 1/5/3", got.MinLength, got.MaxLength, got.MeanLength)
+	}
+	if got.BinWidth != 0.1 || len(got.Bins) != rayHistogramBins {
+		t.Errorf("got %d bins of %g, want %d bins of 0.1", len(got.Bins), got.BinWidth, rayHistogramBins)
+	}
+	total := 0
+	for _, count := range got.Counts {
+		total += count
+	}
+	if total != 6 {
+		t.Errorf("histogram holds %d rays, want 6", total)
+	}
+	if got.Counts[rayHistogramBins-1] != 1 {
+		t.Errorf("longest ray should fall in the last bin, counts = %v", got.Counts)
+	}
+
+	if _, err := ParseRayAtom("Ray atom output\nno rays\n"); err == nil {
+		t.Error("expected an error for output without rays")
+	}
+}
+
+func TestParseVoronoiNetwork(t *testing.T) {
+	got, err := ParseVoronoiNetwork(readFixture(t, "EDI.nt2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &VoronoiNetworkResult{
+		NumberOfNodes: 2,
+		NumberOfEdges: 2,
+		Nodes: []VoronoiNode{
+			{ID: 0, X: 1, Y: 2, Z: 3, Radius: 1.2, Atoms: []int{1, 2, 3, 4}},
+			{ID: 1, X: 4, Y: 5, Z: 6, Radius: 0.8, Atoms: []int{2, 3, 4, 5}},
+		},
+		Edges: []VoronoiEdge{
+			{From: 0, To: 1, Radius: 0.7, Shift: [3]int{0, 0, 1}, Length: 5.19},
+			{From: 1, To: 0, Radius: 0.7, Shift: [3]int{0, 0, -1}, Length: 5.19},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := ParseVoronoiNetwork("Edge table:\n0 -> 1 0.7\n"); err == nil {
+		t.Error("expected an error for a malformed edge")
+	}
+}
@@ -0,0 +1 @@
+EDI.res    4.89082 3.03868  4.89082
@@ -2,15 +2,22 @@ package pool
 
 import (
 	"context"
+	"errors"
 	"runtime"
 	"sync"
 	"sync/atomic"
 )
 
+// ErrQueueFull is returned by TrySubmit when the task queue has no free slot
+var ErrQueueFull = errors.New("task queue is full")
+
 type Task struct {
 	ID       string
 	Func     func() error
 	Priority int
+	// Cancel, if set, is called instead of Func for a task still queued when
+	// the pool stops, so that whoever waits on the task can be released
+	Cancel func()
 }
 
 type WorkerPool struct {
@@ -20,17 +27,30 @@ type WorkerPool struct {
 	ctx           context.Context
 	cancel        context.CancelFunc
 	activeWorkers int64
+	// stopMu makes submitting and stopping exclusive, so no task can be
+	// queued after Stop has drained the queue
+	stopMu  sync.RWMutex
+	stopped bool
 }
 
 func NewWorkerPool(workers int) *WorkerPool {
+	return NewWorkerPoolWithQueue(workers, 0)
+}
+
+// NewWorkerPoolWithQueue creates a pool whose queue holds at most queueSize
+// pending tasks; a non-positive size falls back to twice the worker count
+func NewWorkerPoolWithQueue(workers, queueSize int) *WorkerPool {
 	if workers <= 0 {
 		workers = runtime.NumCPU()
 	}
+	if queueSize <= 0 {
+		queueSize = workers * 2
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	return &WorkerPool{
 		workers:   workers,
-		taskQueue: make(chan Task, workers*2),
+		taskQueue: make(chan Task, queueSize),
 		ctx:       ctx,
 		cancel:    cancel,
 	}
@@ -43,13 +63,37 @@ func (wp *WorkerPool) Start() {
 	}
 }
 
+// Stop makes the workers exit once their current task is done, then
+// cancels the tasks still queued. The queue is left open so that a
+// concurrent Submit fails rather than panics.
 func (wp *WorkerPool) Stop() {
+	// Cancel first so that submitters blocked on a full queue let go of
+	// stopMu
 	wp.cancel()
-	close(wp.taskQueue)
+	wp.stopMu.Lock()
+	wp.stopped = true
+	wp.stopMu.Unlock()
 	wp.wg.Wait()
+
+	for {
+		select {
+		case task := <-wp.taskQueue:
+			if task.Cancel != nil {
+				task.Cancel()
+			}
+		default:
+			return
+		}
+	}
 }
 
 func (wp *WorkerPool) Submit(task Task) error {
+	wp.stopMu.RLock()
+	defer wp.stopMu.RUnlock()
+	if wp.stopped {
+		return context.Canceled
+	}
+
 	select {
 	case wp.taskQueue <- task:
 		return nil
@@ -58,7 +102,36 @@ func (wp *WorkerPool) Submit(task Task) error {
 	}
 }
 
+// TrySubmit queues the task without blocking, returning ErrQueueFull when
+// the queue is at capacity
+func (wp *WorkerPool) TrySubmit(task Task) error {
+	wp.stopMu.RLock()
+	defer wp.stopMu.RUnlock()
+	if wp.stopped {
+		return context.Canceled
+	}
+
+	select {
+	case <-wp.ctx.Done():
+		return context.Canceled
+	default:
+	}
+
+	select {
+	case wp.taskQueue <- task:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
 func (wp *WorkerPool) SubmitWithContext(ctx context.Context, task Task) error {
+	wp.stopMu.RLock()
+	defer wp.stopMu.RUnlock()
+	if wp.stopped {
+		return context.Canceled
+	}
+
 	select {
 	case wp.taskQueue <- task:
 		return nil
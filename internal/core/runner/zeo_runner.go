@@ -2,23 +2,37 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"zeo-api/internal/config"
 	"zeo-api/internal/utils/file"
 )
 
+// StructureBaseName is the file name, without extension, the structure is
+// given inside a job directory. Outputs Zeo++ derives from the input name
+// start with it.
+const StructureBaseName = "structure"
+
 type ZeoRunner struct {
 	config *config.ZeoConfig
+
+	versionOnce sync.Once
+	version     string
+	versionErr  error
 }
 
 type ZeoResult struct {
 	Success     bool
 	ExitCode    int
+	Duration    time.Duration
+	TimedOut    bool // killed for exceeding the configured timeout
 	Stdout      string
 	Stderr      string
 	OutputFiles map[string][]byte
@@ -29,23 +43,46 @@ func NewZeoRunner(cfg *config.ZeoConfig) *ZeoRunner {
 }
 
 func (zr *ZeoRunner) RunCommand(ctx context.Context, structureFile string, args []string, outputFiles []string) (*ZeoResult, error) {
+	return zr.RunCommandWithInputs(ctx, structureFile, args, outputFiles, nil)
+}
+
+// RunCommandWithInputs is RunCommand with extra input files, keyed by name,
+// written into the job directory next to the structure before Zeo++ runs
+func (zr *ZeoRunner) RunCommandWithInputs(ctx context.Context, structureFile string, args []string, outputFiles []string, inputFiles map[string][]byte) (*ZeoResult, error) {
 	// Ensure workspace exists
 	if err := os.MkdirAll(zr.config.Workdir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create workspace: %w", err)
 	}
 
-	// Copy structure file to workspace
-	workspaceFile := filepath.Join(zr.config.Workdir, filepath.Base(structureFile))
-	if err := zr.copyFile(structureFile, workspaceFile); err != nil {
-		return nil, fmt.Errorf("failed to copy structure file: %w", err)
+	// Every run gets its own scratch directory so that concurrent runs
+	// writing the same output names never see each other's files
+	jobDir, err := os.MkdirTemp(zr.config.Workdir, "job_")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job directory: %w", err)
 	}
 	defer func() {
-		_ = os.Remove(workspaceFile)
+		_ = os.RemoveAll(jobDir)
 	}()
 
-	// Prepare command arguments
+	// Copy structure file to job directory under a fixed name, so outputs
+	// Zeo++ names after its input are predictable
+	structureName := StructureBaseName + file.StructureExtension(structureFile)
+	if err := zr.copyFile(structureFile, filepath.Join(jobDir, structureName)); err != nil {
+		return nil, fmt.Errorf("failed to copy structure file: %w", err)
+	}
+
+	for name, content := range inputFiles {
+		if name != filepath.Base(name) || name == structureName {
+			return nil, fmt.Errorf("invalid input file name: %q", name)
+		}
+		if err := os.WriteFile(filepath.Join(jobDir, name), content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write input file %s: %w", name, err)
+		}
+	}
+
+	// Prepare command arguments; paths are relative to the job directory
 	fullArgs := append([]string{}, args...)
-	fullArgs = append(fullArgs, workspaceFile)
+	fullArgs = append(fullArgs, structureName)
 
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(ctx, zr.config.Timeout)
@@ -53,12 +90,17 @@ func (zr *ZeoRunner) RunCommand(ctx context.Context, structureFile string, args
 
 	// Execute command
 	cmd := exec.CommandContext(ctx, zr.config.ExecutablePath, fullArgs...)
-	cmd.Dir = zr.config.Workdir
+	cmd.Dir = jobDir
+	// Don't let orphaned child processes holding the output pipe keep a
+	// cancelled run alive
+	cmd.WaitDelay = 2 * time.Second
 
+	start := time.Now()
 	stdout, err := cmd.CombinedOutput()
 
 	result := &ZeoResult{
 		Success:     err == nil,
+		Duration:    time.Since(start),
 		Stdout:      string(stdout),
 		Stderr:      "",
 		OutputFiles: make(map[string][]byte),
@@ -71,17 +113,20 @@ func (zr *ZeoRunner) RunCommand(ctx context.Context, structureFile string, args
 			result.ExitCode = -1
 		}
 		result.Stderr = err.Error()
+		result.TimedOut = errors.Is(ctx.Err(), context.DeadlineExceeded)
 	}
 
-	// Collect output files
+	// Collect output files from the job directory only
 	maxFileSize := int64(100 * 1024 * 1024) // 100MB limit
-	for _, outputFile := range outputFiles {
-		outputPath := filepath.Join(zr.config.Workdir, outputFile)
+	absJobDir, err := filepath.Abs(jobDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve job directory: %w", err)
+	}
+	for _, outputFile := range zr.expandOutputFiles(absJobDir, outputFiles) {
+		outputPath := filepath.Join(absJobDir, outputFile)
 
-		// Ensure output file is within workspace
-		absOutputPath, err := filepath.Abs(filepath.Clean(outputPath))
-		absWorkdir, err2 := filepath.Abs(filepath.Clean(zr.config.Workdir))
-		if err != nil || err2 != nil || !strings.HasPrefix(absOutputPath, absWorkdir) {
+		// Ensure output file is within the job directory
+		if !strings.HasPrefix(filepath.Clean(outputPath), absJobDir+string(filepath.Separator)) {
 			continue
 		}
 
@@ -89,7 +134,6 @@ func (zr *ZeoRunner) RunCommand(ctx context.Context, structureFile string, args
 			// Check file size
 			info, err := os.Stat(outputPath)
 			if err != nil || info.Size() > maxFileSize {
-				_ = os.Remove(outputPath)
 				continue
 			}
 
@@ -98,13 +142,32 @@ func (zr *ZeoRunner) RunCommand(ctx context.Context, structureFile string, args
 				continue // Skip files that can't be read
 			}
 			result.OutputFiles[outputFile] = content
-			_ = os.Remove(outputPath) // Clean up after reading
 		}
 	}
 
 	return result, nil
 }
 
+// expandOutputFiles resolves glob patterns among the requested output files
+// against the job directory; plain names are passed through unchanged
+func (zr *ZeoRunner) expandOutputFiles(jobDir string, outputFiles []string) []string {
+	var expanded []string
+	for _, outputFile := range outputFiles {
+		if !strings.ContainsAny(outputFile, "*?[") {
+			expanded = append(expanded, outputFile)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(jobDir, outputFile))
+		if err != nil {
+			continue
+		}
+		for _, match := range matches {
+			expanded = append(expanded, filepath.Base(match))
+		}
+	}
+	return expanded
+}
+
 func (zr *ZeoRunner) copyFile(src, dst string) error {
 	input, err := os.ReadFile(src)
 	if err != nil {
@@ -118,14 +181,48 @@ func (zr *ZeoRunner) ValidateZeoExecutable() error {
 	return err
 }
 
+// Version identifies the Zeo++ binary by the SHA-256 of the executable, since
+// Zeo++ has no version flag. The value is computed once and reused.
+func (zr *ZeoRunner) Version() (string, error) {
+	zr.versionOnce.Do(func() {
+		path, err := exec.LookPath(zr.config.ExecutablePath)
+		if err != nil {
+			zr.versionErr = err
+			return
+		}
+		zr.version, zr.versionErr = file.GenerateFileHash(path)
+	})
+	return zr.version, zr.versionErr
+}
+
 func BuildZeoArgs(analysisType string, params map[string]interface{}) ([]string, error) {
 	var args []string
 
-	// Add high accuracy flag if specified
-	if ha, ok := params["ha"].(bool); ok && ha {
+	// Add high accuracy flag if specified, optionally with a named setting
+	if mode, ok := params["ha_mode"].(string); ok {
+		if !isHAMode(mode) {
+			return nil, fmt.Errorf("unsupported ha_mode %q. Supported: %s", mode, strings.Join(HAModes, ", "))
+		}
+		args = append(args, "-ha", mode)
+	} else if ha, ok := params["ha"].(bool); ok && ha {
 		args = append(args, "-ha")
 	}
 
+	// Atom radii and mass overrides, materialized by BuildInputFiles
+	pointAtoms, _ := params["point_atoms"].(bool)
+	if _, ok := params["radii"].(string); ok {
+		if pointAtoms {
+			return nil, fmt.Errorf("radii cannot be combined with point_atoms")
+		}
+		args = append(args, "-r", RadiiFileName)
+	}
+	if pointAtoms {
+		args = append(args, "-nor")
+	}
+	if _, ok := params["masses"].(string); ok {
+		args = append(args, "-mass", MassFileName)
+	}
+
 	switch analysisType {
 	case "pore_diameter":
 		args = append(args, "-res", "output.res")
@@ -167,6 +264,20 @@ func BuildZeoArgs(analysisType string, params map[string]interface{}) ([]string,
 			return nil, err
 		}
 		args = append(args, "-volpo", fmt.Sprintf("%.2f", probeRadius), fmt.Sprintf("%.2f", chanRadius), fmt.Sprintf("%d", samples), "output.volpo")
+	case "ray_atom":
+		probeRadius := getFloatParam(params, "probe_radius", 1.21)
+		if err := validateFloatParam(probeRadius, 0.1, 10.0, "probe_radius"); err != nil {
+			return nil, err
+		}
+		chanRadius := getFloatParam(params, "chan_radius", 1.21)
+		if err := validateFloatParam(chanRadius, 0.1, 10.0, "chan_radius"); err != nil {
+			return nil, err
+		}
+		samples := getIntParam(params, "samples", 50000)
+		if err := validateIntParam(samples, 100, 1000000, "samples"); err != nil {
+			return nil, err
+		}
+		args = append(args, "-ray_atom", fmt.Sprintf("%.2f", chanRadius), fmt.Sprintf("%.2f", probeRadius), fmt.Sprintf("%d", samples), "output.ray_atom")
 	case "channel_analysis":
 		probeRadius := getFloatParam(params, "probe_radius", 1.21)
 		if err := validateFloatParam(probeRadius, 0.1, 10.0, "probe_radius"); err != nil {
@@ -188,7 +299,18 @@ func BuildZeoArgs(analysisType string, params map[string]interface{}) ([]string,
 		if err := validateIntParam(samples, 1000, 1000000, "samples"); err != nil {
 			return nil, err
 		}
-		args = append(args, "-psd", fmt.Sprintf("%.2f", probeRadius), fmt.Sprintf("%.2f", chanRadius), "100", fmt.Sprintf("%d", samples), "output.psd")
+		// Zeo++ bins the histogram at a fixed width; bins coarsens it after
+		// parsing and is only validated here
+		if err := requireNumber(params, "bins"); err != nil {
+			return nil, err
+		}
+		if _, ok := params["bins"]; ok {
+			if err := validateIntParam(getIntParam(params, "bins", 0), 1, 1000, "bins"); err != nil {
+				return nil, err
+			}
+		}
+		// Zeo++ writes the histogram to output.psd_histo
+		args = append(args, "-psd", fmt.Sprintf("%.2f", chanRadius), fmt.Sprintf("%.2f", probeRadius), fmt.Sprintf("%d", samples), "output.psd")
 	case "blocking_spheres":
 		probeRadius := getFloatParam(params, "probe_radius", 1.86)
 		if err := validateFloatParam(probeRadius, 0.1, 10.0, "probe_radius"); err != nil {
@@ -197,6 +319,24 @@ func BuildZeoArgs(analysisType string, params map[string]interface{}) ([]string,
 		args = append(args, "-block", fmt.Sprintf("%.2f", probeRadius), "output.block")
 	case "open_metal_sites":
 		args = append(args, "-oms", "output.oms")
+	case "voronoi_network":
+		args = append(args, "-nt2", "output.nt2")
+	case "convert":
+		format, _ := params["format"].(string)
+		if !conversionFormats[format] {
+			return nil, fmt.Errorf("unsupported conversion format %q. Supported: cif, cssr, v1, xyz", format)
+		}
+		args = append(args, "-"+format, "output."+format)
+	case "grid_gaussian", "grid_bov":
+		flag := "-gridG"
+		if analysisType == "grid_bov" {
+			flag = "-gridBOV"
+		}
+		// -gridG and -gridBOV take no arguments; Zeo++ picks the spacing
+		if _, ok := params["grid_spacing"]; ok {
+			return nil, fmt.Errorf("grid_spacing is not supported: Zeo++ does not let the grid spacing be configured")
+		}
+		args = append(args, flag)
 	default:
 		return nil, fmt.Errorf("unsupported analysis type: %s", analysisType)
 	}
@@ -204,6 +344,50 @@ func BuildZeoArgs(analysisType string, params map[string]interface{}) ([]string,
 	return args, nil
 }
 
+// HAModes are the named high accuracy settings Zeo++ accepts after -ha
+var HAModes = []string{
+	"OCC", "FCC", "ACC", "AQC", "DDH", "TIH", "ICH", "ICC", "ICO",
+	"HI", "MED", "LOW",
+	"S4", "S10", "S20", "S30", "S40", "S50", "S100", "S1000", "S10000",
+}
+
+func isHAMode(mode string) bool {
+	for _, m := range HAModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// Names the radii and mass tables are written under in the job directory
+const (
+	RadiiFileName = "radii.rad"
+	MassFileName  = "mass.mass"
+)
+
+// BuildInputFiles returns the auxiliary files the arguments from BuildZeoArgs
+// refer to, keyed by file name
+func BuildInputFiles(params map[string]interface{}) map[string][]byte {
+	inputFiles := make(map[string][]byte)
+	if radii, ok := params["radii"].(string); ok {
+		inputFiles[RadiiFileName] = []byte(radii)
+	}
+	if masses, ok := params["masses"].(string); ok {
+		inputFiles[MassFileName] = []byte(masses)
+	}
+	return inputFiles
+}
+
+// conversionFormats are the structure formats Zeo++ can write, keyed by the
+// flag (without dash) that selects them
+var conversionFormats = map[string]bool{
+	"cif":  true,
+	"cssr": true,
+	"v1":   true,
+	"xyz":  true,
+}
+
 func validateFloatParam(value float64, min, max float64, name string) error {
 	if value < min || value > max {
 		return fmt.Errorf("%s must be between %.2f and %.2f", name, min, max)
@@ -218,6 +402,15 @@ func validateIntParam(value int, min, max int, name string) error {
 	return nil
 }
 
+// requireNumber rejects a parameter handlers kept as the raw form value
+// because it did not parse as a number
+func requireNumber(params map[string]interface{}, key string) error {
+	if raw, ok := params[key].(string); ok {
+		return fmt.Errorf("%s must be a number, got %q", key, raw)
+	}
+	return nil
+}
+
 func getFloatParam(params map[string]interface{}, key string, defaultValue float64) float64 {
 	if val, ok := params[key].(float64); ok {
 		return val
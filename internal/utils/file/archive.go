@@ -0,0 +1,208 @@
+package file
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ExtractedFile is a structure file unpacked from an archive
+type ExtractedFile struct {
+	Name string // path inside the archive
+	Path string // path on disk
+}
+
+// ArchiveLimits bounds what ExtractStructures will unpack
+type ArchiveLimits struct {
+	MaxFiles     int
+	MaxFileSize  int64
+	MaxTotalSize int64
+}
+
+// ErrInvalidArchive matches, via errors.Is, the errors ExtractStructures
+// returns for problems with the archive itself rather than the server
+var ErrInvalidArchive = errors.New("invalid archive")
+
+type invalidArchiveError struct {
+	err error
+}
+
+func (e *invalidArchiveError) Error() string { return e.err.Error() }
+
+func (e *invalidArchiveError) Unwrap() []error { return []error{ErrInvalidArchive, e.err} }
+
+func invalidArchive(format string, args ...interface{}) error {
+	return &invalidArchiveError{err: fmt.Errorf(format, args...)}
+}
+
+func IsValidArchiveFile(filename string) bool {
+	filename = strings.ToLower(filename)
+	return strings.HasSuffix(filename, ".zip") ||
+		strings.HasSuffix(filename, ".tar.gz") ||
+		strings.HasSuffix(filename, ".tgz")
+}
+
+// ExtractStructures unpacks the structure files of a .zip or .tar.gz archive
+// into destDir, skipping directories, hidden files and unsupported formats.
+// Files are renamed on disk so entries with the same base name never clash.
+func ExtractStructures(archivePath, destDir string, limits ArchiveLimits) ([]ExtractedFile, error) {
+	if err := os.MkdirAll(destDir, 0700); err != nil {
+		return nil, err
+	}
+
+	x := &extractor{destDir: destDir, limits: limits}
+	lower := strings.ToLower(archivePath)
+	var err error
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		err = x.extractZip(archivePath)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		err = x.extractTarGz(archivePath)
+	default:
+		err = invalidArchive("unsupported archive format. Supported: .zip, .tar.gz, .tgz")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(x.files) == 0 {
+		return nil, invalidArchive("archive contains no supported structure files")
+	}
+	return x.files, nil
+}
+
+type extractor struct {
+	destDir   string
+	limits    ArchiveLimits
+	files     []ExtractedFile
+	totalSize int64
+}
+
+func (x *extractor) extractZip(archivePath string) error {
+	zr, err := zip.OpenReader(archivePath)
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return err
+	}
+	if err != nil {
+		return invalidArchive("failed to open zip archive: %w", err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() || !x.wanted(f.Name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return invalidArchive("failed to read %s: %w", f.Name, err)
+		}
+		err = x.add(f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *extractor) extractTarGz(archivePath string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return invalidArchive("failed to open tar.gz archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return invalidArchive("failed to read tar.gz archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg || !x.wanted(hdr.Name) {
+			continue
+		}
+		if err := x.add(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func (x *extractor) wanted(name string) bool {
+	for _, part := range strings.Split(path.Clean(name), "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" {
+			return false
+		}
+	}
+	return IsValidStructureFile(name)
+}
+
+func (x *extractor) add(name string, r io.Reader) error {
+	if x.limits.MaxFiles > 0 && len(x.files) >= x.limits.MaxFiles {
+		return invalidArchive("archive contains more than %d structure files", x.limits.MaxFiles)
+	}
+
+	diskName := fmt.Sprintf("%06d_%s", len(x.files), sanitizeFilename(path.Base(name)))
+	diskPath := filepath.Join(x.destDir, diskName)
+	dst, err := os.Create(diskPath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	// Read one byte past the limit to detect oversized entries without
+	// trusting sizes declared in archive headers
+	limit := x.limits.MaxFileSize
+	if limit <= 0 {
+		limit = 1 << 62
+	}
+	src := &sourceReader{r: io.LimitReader(r, limit+1)}
+	n, err := io.Copy(dst, src)
+	if src.err != nil {
+		return invalidArchive("failed to extract %s: %w", name, src.err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", name, err)
+	}
+	if n > limit {
+		return invalidArchive("%s exceeds the maximum file size of %d bytes", name, limit)
+	}
+	x.totalSize += n
+	if x.limits.MaxTotalSize > 0 && x.totalSize > x.limits.MaxTotalSize {
+		return invalidArchive("archive exceeds the maximum extracted size of %d bytes", x.limits.MaxTotalSize)
+	}
+
+	x.files = append(x.files, ExtractedFile{Name: path.Clean(name), Path: diskPath})
+	return nil
+}
+
+// sourceReader remembers read errors, which come from a corrupt archive,
+// so they can be told apart from errors writing to disk
+type sourceReader struct {
+	r   io.Reader
+	err error
+}
+
+func (s *sourceReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if err != nil && err != io.EOF {
+		s.err = err
+	}
+	return n, err
+}
@@ -43,12 +43,7 @@ func SaveUploadedFile(file *multipart.FileHeader, prefix string) (string, error)
 
 	// Generate unique filename
 	uniqueID := fmt.Sprintf("%s_%d_%d", prefix, time.Now().UnixNano(), rand.Intn(10000))
-	ext := filepath.Ext(safeName)
-	if strings.HasSuffix(strings.ToLower(safeName), ".gz") {
-		// Handle gzipped files properly
-		base := strings.TrimSuffix(safeName, ".gz")
-		ext = filepath.Ext(base) + ".gz"
-	}
+	ext := StructureExtension(safeName)
 
 	// Ensure workspace directory exists
 	workspace := "./workspace"
@@ -61,7 +56,7 @@ func SaveUploadedFile(file *multipart.FileHeader, prefix string) (string, error)
 	fullPath := filepath.Join(workspace, filename)
 
 	// Validate final path
-	if !strings.HasPrefix(fullPath, workspace) {
+	if !strings.HasPrefix(fullPath, filepath.Clean(workspace)+string(filepath.Separator)) {
 		return "", fmt.Errorf("invalid file path")
 	}
 
@@ -79,6 +74,16 @@ func SaveUploadedFile(file *multipart.FileHeader, prefix string) (string, error)
 	return fullPath, nil
 }
 
+// StructureExtension returns the extension of a structure file name,
+// keeping the inner extension of gzipped files (".cif.gz")
+func StructureExtension(filename string) string {
+	if strings.HasSuffix(strings.ToLower(filename), ".gz") {
+		base := strings.TrimSuffix(filename, filepath.Ext(filename))
+		return filepath.Ext(base) + filepath.Ext(filename)
+	}
+	return filepath.Ext(filename)
+}
+
 func sanitizeFilename(filename string) string {
 	// Remove path separators and sanitize
 	filename = filepath.Base(filename)